@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	vo "github.com/maroda/verificat/obvy"
 	verificat "github.com/maroda/verificat/server"
@@ -14,6 +20,9 @@ const (
 	app        = "verificat"
 	runPort    = "4330"
 	llvl       = slog.LevelInfo
+
+	// shutdownGrace is how long in-flight requests get to finish after a signal
+	shutdownGrace = 15 * time.Second
 )
 
 func init() {
@@ -44,10 +53,55 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Load the Production Readiness Checklist, if one is configured.
+	// Without CHECKLIST set, the built-in default checklist is used.
+	var checklist *verificat.Checklist
+	if path := os.Getenv("CHECKLIST"); path != "" {
+		checklist, err = verificat.LoadChecklist(path)
+		if err != nil {
+			slog.Error("Error loading checklist", slog.Any("error", err), slog.String("filename", path))
+			os.Exit(1)
+		}
+	}
+
+	// Unless every repo is read from a local mirror, Checks read GitHub,
+	// so missing credentials stop startup rather than failing each verification later.
+	if checklist == nil || checklist.ReadsGitHub() {
+		if err := verificat.CheckGitHubAuth(); err != nil {
+			slog.Error("Error configuring GitHub", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	// Every request context is derived from this one,
+	// so a shutdown signal cancels any verification still in flight.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// A NewVerificationServ is configured with the database on local disk
-	server := verificat.NewVerificationServ(store)
-	if err := http.ListenAndServe(":"+runPort, server); err != nil {
+	server := &http.Server{
+		Addr:        ":" + runPort,
+		Handler:     verificat.NewVerificationServ(store, checklist),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	// Shutdown stops accepting requests and waits for in-flight ones,
+	// which are already cancelled through ctx, up to shutdownGrace.
+	idle := make(chan struct{})
+	go func() {
+		defer close(idle)
+		<-ctx.Done()
+		slog.Info("Stopping Verificat", slog.Duration("grace", shutdownGrace))
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Verification Service did not stop cleanly", slog.Any("error", err))
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slog.Error("Could not start Verification Service", slog.Any("error", err))
 		os.Exit(1)
 	}
+	<-idle
 }
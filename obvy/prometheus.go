@@ -14,6 +14,8 @@ type StatsInternal struct {
 	WWWStats    *prometheus.CounterVec
 	PollSingle  prometheus.Counter
 	PollTimer   prometheus.Histogram
+	CheckStats  *prometheus.CounterVec
+	GitHubQuota *prometheus.GaugeVec
 }
 
 func NewStatsInternal() *StatsInternal {
@@ -45,6 +47,22 @@ func NewStatsInternal() *StatsInternal {
 		prometheus.HistogramOpts{Name: "poll_requests_seconds"})
 	si.WWWRegistry.MustRegister(si.PollTimer)
 
+	// Every check result by status, so an upstream outage (error)
+	// can be told apart from a readiness regression (fail).
+	si.CheckStats = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "check_results_total"},
+		[]string{"check", "status"},
+	)
+	si.WWWRegistry.MustRegister(si.CheckStats)
+
+	// What's left of the GitHub rate limit, as of the last answer,
+	// for each API and rate limit resource, e.g.: core
+	si.GitHubQuota = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "github_ratelimit_remaining"},
+		[]string{"api", "resource"},
+	)
+	si.WWWRegistry.MustRegister(si.GitHubQuota)
+
 	return si
 }
 
@@ -61,6 +79,14 @@ func (si *StatsInternal) RecPollTimer(duration float64) {
 	si.PollSingle.Inc()
 }
 
+func (si *StatsInternal) RecCheck(check, status string) {
+	si.CheckStats.WithLabelValues(check, status).Inc()
+}
+
+func (si *StatsInternal) RecGitHubQuota(api, resource string, remaining int) {
+	si.GitHubQuota.WithLabelValues(api, resource).Set(float64(remaining))
+}
+
 func (si *StatsInternal) Handler() http.Handler {
 	return promhttp.HandlerFor(si.WWWRegistry, promhttp.HandlerOpts{})
 }
@@ -0,0 +1,84 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Bitbucket Cloud is read by default.
+// A token in BITBUCKET_TOKEN is sent as a bearer token, e.g.: a repository or workspace access token.
+const (
+	bbAPI      = "https://api.bitbucket.org/2.0"
+	bbTokenEnv = "BITBUCKET_TOKEN"
+)
+
+// bbCodeownersLocations are where Bitbucket looks for CODEOWNERS, in the order it looks.
+// https://support.atlassian.com/bitbucket-cloud/docs/set-up-and-use-code-owners/
+var bbCodeownersLocations = []string{"CODEOWNERS", ".bitbucket/CODEOWNERS"}
+
+// BitbucketClient reads repos through the Bitbucket Cloud REST API.
+// A repo's org is its workspace, e.g.: bitbucket:maroda/admin
+type BitbucketClient struct {
+	*forgeHTTP
+	Token string // The environment variable holding the token
+}
+
+// newBitbucketClient is the newForge for the "bitbucket" type.
+func newBitbucketClient(fc ForgeConfig) Forge {
+	return &BitbucketClient{forgeHTTP: newForgeHTTP(fc.API), Token: fc.Token}
+}
+
+// repository is the API URL of a repo.
+func (bb *BitbucketClient) repository(ref RepoRef) string {
+	return bb.API + "/repositories/" + url.PathEscape(ref.Org) + "/" + url.PathEscape(ref.Repo)
+}
+
+// header carries the token, without one only public repos can be read.
+func (bb *BitbucketClient) header() http.Header {
+	header := http.Header{"Accept": {"application/json"}}
+	if token := fillEnvVar(bb.Token); token != "ENOENT" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return header
+}
+
+func (bb *BitbucketClient) DefaultBranch(ctx context.Context, ref RepoRef) (string, error) {
+	var repo struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := bb.getRepo(ctx, bb.repository(ref), fixedHeader(bb.header()), &repo); err != nil {
+		return "", err
+	}
+	if repo.MainBranch.Name == "" {
+		return "", fmt.Errorf("no main branch for repo at %s", bb.repository(ref))
+	}
+
+	slog.Debug("Default Branch Found", slog.String("URL", bb.repository(ref)), slog.String("Branch", repo.MainBranch.Name))
+	return repo.MainBranch.Name, nil
+}
+
+// Contents fetches a file raw with the src API.
+func (bb *BitbucketClient) Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error) {
+	target := bb.repository(ref) + "/src/" + url.PathEscape(branch) + "/" + escapePath(path)
+
+	body, err := bb.get(ctx, target, bb.header())
+	return body, target, err
+}
+
+func (bb *BitbucketClient) RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error) {
+	return repoFile(ctx, bb, ref, paths)
+}
+
+func (bb *BitbucketClient) CodeownersLocations() []string {
+	return bbCodeownersLocations
+}
+
+// ParseCodeowners reads Bitbucket's CODEOWNERS, which uses the same syntax as GitHub.
+func (bb *BitbucketClient) ParseCodeowners(data string) *Codeowners {
+	return ParseCodeowners(data)
+}
@@ -1,46 +1,131 @@
 package verificat
 
 import (
+	"context"
+	"fmt"
+	"iter"
 	"log/slog"
+	"maps"
+	"net/http"
 	"time"
+
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
 )
 
 // SvcCat contains methods for operating with the Service Catalog, e.g. Backstage API.
 type SvcCat interface {
-	ReadSvc() (string, error)
+	ReadSvc(ctx context.Context) (string, error)
 }
 
 // SvcConfig is the Client Configuration
 type SvcConfig struct {
-	URL      string // URL is the Backstage API endpoint
-	Service  string // Each Service is known as the "Component" in Backstage
-	Datetime int64  // Unix Epoch in seconds
-	Owner    string // Should equal CODEOWNERS for this repo in GitHub
+	URL       string         // URL is the Backstage API endpoint
+	Namespace string         // The Backstage namespace, "default" if empty
+	Token     string         // Optional bearer token for the Backstage API
+	Service   string         // An entity ref, e.g.: admin or component:default/admin-web, see EntityRef
+	Name      string         // The bare entity name, e.g.: admin-web, set when the Service is read
+	Datetime  int64          // Unix Epoch in seconds
+	Owner     string         // Should equal CODEOWNERS for this repo in GitHub
+	Repos     []RepoRef      // The repos this Service lives in, the first is where it's owned
+	Members   []CatalogEntry // The Components of a System, its Score is rolled up from theirs
 }
 
-// ReadSvc can query Backstage for a chunk of data about a System,
-// i.e. the "top-level" Service.
+// ReadSvc can query Backstage for a chunk of data about an entity,
+// by default a System, i.e. the "top-level" Service.
 // Each method called for filling in data adds the entry to the SvcConfig struct.
-func (sc *SvcConfig) ReadSvc() (string, error) {
+// Service is replaced with how the entity is known in the almanac, see EntityRef.Key
+// A Service that isn't in Backstage returns SystemNotRecognized.
+func (sc *SvcConfig) ReadSvc(ctx context.Context) (string, error) {
 	sc.Datetime = time.Now().Unix()
 
-	/* removing Backstage slowly ... this will be a generic comparison to an example
+	ref, err := ParseEntityRef(sc.Service)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", SystemNotRecognized, err)
+	}
+
+	c, err := newBackstageClient(sc.URL, sc.Namespace, sc.Token)
+	if err != nil {
+		return "", err
+	}
 
-	c, _ := backstage.NewClient(sc.URL, "default", nil)
-	owner, _, err := ReadSystemBS(sc.Service, c)
+	ref = ref.WithDefaults("system", c.DefaultNamespace)
+	se, err := ReadEntityBS(ctx, ref, c)
+	if err != nil {
+		return "", err
+	}
 
-	*/
+	// The GitHub repo comes along with the owner when it's annotated
+	entry := bsEntry(*se, ref.Namespace, c.DefaultNamespace)
+	sc.Service = entry.Service
+	sc.Name = entry.Name
+	sc.Owner = entry.Owner
+	sc.Repos = entry.Repos
+	slog.Debug("Owner Set", slog.String("Owner", sc.Owner), slog.Any("Repos", sc.Repos))
+
+	// A System is as ready as its least ready Component
+	if ref.Kind == "system" {
+		components, err := bsEntityList(ctx, c, "component", ref.Namespace)
+		if err != nil {
+			return "", err
+		}
+		sc.Members = systemMembers(ref.Name, maps.Values(components))
+	}
 
-	// TODO: Figure out something besides a complicated Backstage setup to test.
-	// Temporarily, return a static string that will match
-	sc.Owner = "maroda"
-	slog.Debug("Owner Set", slog.String("Owner", sc.Owner))
 	return sc.Owner, nil
 }
 
+// systemMembers are the Components that name the System as theirs, sorted.
+func systemMembers(system string, entries iter.Seq[CatalogEntry]) []CatalogEntry {
+	var members []CatalogEntry
+	for entry := range entries {
+		if entry.Kind == "component" && entry.System == system {
+			members = append(members, entry)
+		}
+	}
+	sortEntries(members)
+	return members
+}
+
+// newBackstageClient connects to the Backstage API at url.
+// With a token, every request is sent with it as a bearer token.
+func newBackstageClient(url, namespace, token string) (*backstage.Client, error) {
+	hc := &http.Client{Timeout: webTimeout}
+	if token != "" {
+		hc.Transport = &bearerTransport{token: token, base: http.DefaultTransport}
+	}
+
+	c, err := backstage.NewClient(url, namespace, hc)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Backstage client for %s, %w", url, err)
+	}
+	return c, nil
+}
+
+// bearerTransport adds an Authorization header to every request.
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (bt *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it was given
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+bt.token)
+	return bt.base.RoundTrip(r)
+}
+
+// entityName is the bare name of the Service, for finding its repos, tier, skips, and who's on call.
+// A Service that hasn't been read from the catalog has its name parsed from the ref.
+func (sc *SvcConfig) entityName() string {
+	if sc.Name != "" {
+		return sc.Name
+	}
+	return keyName(sc.Service)
+}
+
 // ReadinessRead is the function that tests this service for Production Readiness
-func ReadinessRead(i SvcCat) (string, error) {
+func ReadinessRead(ctx context.Context, i SvcCat) (string, error) {
 	// Calling ReadSvc() initiates the source data struct, SvcConfig
 	// Currently only returning the Owner, which is what ReadSvc() returns
-	return i.ReadSvc()
+	return i.ReadSvc(ctx)
 }
@@ -1,6 +1,7 @@
 package verificat
 
 import (
+	"context"
 	"errors"
 	"testing"
 )
@@ -14,7 +15,7 @@ type mockSvcConfig struct {
 }
 
 // Mock method that satisfies SvcCat{}
-func (sc *mockSvcConfig) ReadSvc() (string, error) {
+func (sc *mockSvcConfig) ReadSvc(ctx context.Context) (string, error) {
 	return "code-owners-admin", nil
 }
 
@@ -29,13 +30,89 @@ func (sc *mockSvcConfig) wmOwner() string {
 func TestReadinessRead(t *testing.T) {
 	mockC := &mockSvcConfig{URL: "blank", Service: "admin"}
 
-	got, err := ReadinessRead(mockC)
+	got, err := ReadinessRead(context.Background(), mockC)
 	want := "code-owners-admin"
 
 	assertString(t, got, want)
 	assertError(t, err, nil)
 }
 
+// ReadSvc fills in the SvcConfig from a mock Backstage catalog.
+func TestSvcConfig_ReadSvc(t *testing.T) {
+	t.Run("reads the owner and repo of a System", func(t *testing.T) {
+		bs := makeMockBackstage("")
+		defer bs.Close()
+
+		sc := &SvcConfig{URL: bs.URL, Service: "admin"}
+		got, err := ReadinessRead(context.Background(), sc)
+
+		assertError(t, err, nil)
+		assertString(t, got, "code-owners-admin")
+		assertString(t, sc.Owner, "code-owners-admin")
+		assertString(t, sc.Repos[0].String(), "maroda/admin-api")
+	})
+
+	t.Run("lists the Components of a System as members", func(t *testing.T) {
+		bs := makeMockBackstage("")
+		defer bs.Close()
+
+		sc := &SvcConfig{URL: bs.URL, Service: "admin"}
+		_, err := ReadinessRead(context.Background(), sc)
+
+		assertError(t, err, nil)
+		assertIDEquals(t, len(sc.Members), 1)
+		assertString(t, sc.Members[0].Service, "component:default/admin-web")
+	})
+
+	t.Run("reads any entity by its ref", func(t *testing.T) {
+		bs := makeMockBackstage("")
+		defer bs.Close()
+
+		sc := &SvcConfig{URL: bs.URL, Service: "api:payments/ledger-api"}
+		got, err := ReadinessRead(context.Background(), sc)
+
+		assertError(t, err, nil)
+		assertString(t, got, "code-owners-ledger")
+		assertString(t, sc.Service, "api:payments/ledger-api")
+		assertIDEquals(t, len(sc.Members), 0)
+	})
+
+	t.Run("uses the configured namespace", func(t *testing.T) {
+		bs := makeMockBackstage("")
+		defer bs.Close()
+
+		sc := &SvcConfig{URL: bs.URL, Namespace: "payments", Service: "ledger"}
+		_, err := ReadinessRead(context.Background(), sc)
+
+		assertError(t, err, nil)
+		assertString(t, sc.Repos[0].Branch, "develop")
+	})
+
+	t.Run("sends the bearer token", func(t *testing.T) {
+		bs := makeMockBackstage("mock-token")
+		defer bs.Close()
+
+		sc := &SvcConfig{URL: bs.URL, Token: "mock-token", Service: "admin"}
+		_, err := ReadinessRead(context.Background(), sc)
+		assertError(t, err, nil)
+
+		sc = &SvcConfig{URL: bs.URL, Service: "admin"}
+		_, err = ReadinessRead(context.Background(), sc)
+		assertGotError(t, err)
+	})
+
+	t.Run("an unknown System is not recognized", func(t *testing.T) {
+		bs := makeMockBackstage("")
+		defer bs.Close()
+
+		for _, service := range []string{"Mattic", "component:admin", "group:sre"} {
+			sc := &SvcConfig{URL: bs.URL, Service: service}
+			_, err := ReadinessRead(context.Background(), sc)
+			assertError(t, err, SystemNotRecognized)
+		}
+	})
+}
+
 func assertError(t testing.TB, got, want error) {
 	t.Helper()
 	if !errors.Is(got, want) {
@@ -3,62 +3,112 @@ package verificat
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/tdabasinskas/go-backstage/v2/backstage"
 )
 
-// BSSE is shorthand for the backstage.SystemEntityV1alpha1 type (which is a struct)
-type BSSE *backstage.SystemEntityV1alpha1
-
+// SystemNotRecognized means the catalog has no such entity, whatever its kind.
 var SystemNotRecognized = errors.New("system not recognized")
 
+// projectSlug is the Backstage annotation naming a System's GitHub repo, e.g.: maroda/verificat
+const projectSlug = "github.com/project-slug"
+
 // ReadSystemBS takes a service and returns the service owner
-func ReadSystemBS(wms string, c *backstage.Client) (string, BSSE, error) {
-	// first get a list of systems
-	services, err := bsSystemList(c)
+// The service is a System in the client's namespace, see ReadEntityBS for any other entity.
+func ReadSystemBS(ctx context.Context, wms string, c *backstage.Client) (string, *backstage.Entity, error) {
+	se, err := ReadEntityBS(ctx, EntityRef{Kind: "system", Namespace: c.DefaultNamespace, Name: wms}, c)
 	if err != nil {
 		return "", nil, err
 	}
 
-	// make sure the requested test belongs in the list
-	// if it doesn't belong, the test will appear zeroed out.
-	for _, service := range services {
-		if wms == service {
-			// When there is a match with the System List,
-			// grab the System Entity itself and get the Owner.
-			se, _, err := c.Catalog.Systems.Get(context.Background(), wms, "")
-			if err != nil {
-				slog.Error("Failed to fetch System", slog.Any("Error", err))
-				return "", nil, err
-			}
-			owner := se.Spec.Owner
-			slog.Info("Owner Found", slog.String("Owner", owner))
-			return owner, se, err
-		}
+	owner, _ := se.Spec["owner"].(string)
+	slog.Info("Owner Found", slog.String("Owner", owner))
+	return owner, se, nil
+}
+
+// ReadEntityBS fetches any entity by its full ref, e.g.: component:default/admin-web
+// The entity is looked up with the same filter as a list, so every kind is read the same way.
+func ReadEntityBS(ctx context.Context, ref EntityRef, c *backstage.Client) (*backstage.Entity, error) {
+	filter := "kind=" + ref.Kind + ",metadata.namespace=" + ref.Namespace + ",metadata.name=" + ref.Name
+	entities, _, err := c.Catalog.Entities.List(ctx, &backstage.ListEntityOptions{Filters: []string{filter}})
+	if err != nil {
+		slog.Error("Failed to fetch Entity", slog.String("Entity", ref.String()), slog.Any("Error", err))
+		return nil, err
 	}
 
-	// If we've gotten this far, there wasn't a match.
-	slog.Error("Failed to fetch System", slog.Any("Error", SystemNotRecognized))
-	return "", nil, SystemNotRecognized
+	// If the list is empty, there wasn't a match.
+	if len(entities) == 0 {
+		slog.Error("Failed to fetch Entity", slog.String("Entity", ref.String()), slog.Any("Error", SystemNotRecognized))
+		return nil, fmt.Errorf("%w: %s", SystemNotRecognized, ref)
+	}
+	return &entities[0], nil
 }
 
-// bsSystemList queries Backstage for a list of all System definitions ("kind=system") and returns an array populated with the list.
-// TODO: Make this a map, key = backstage system / value = github repo link
+// bsSystemList queries Backstage for a list of all System definitions ("kind=system") and returns a map populated with the list.
+// The key is the Backstage system, the value is its CatalogEntry, including the GitHub repo from .Metadata.Annotations.github.com/project-slug
 // This way, we can check the real repo for CODEOWNERS instead of defaulting to the 'service name' (which works for some, but not all)
-// This is: .Metadata.Annotations.github.com/project-slug
-func bsSystemList(c *backstage.Client) ([]string, error) {
-	var s []string
+// Only Systems in the client's namespace are listed.
+func bsSystemList(ctx context.Context, c *backstage.Client) (map[string]CatalogEntry, error) {
+	return bsEntityList(ctx, c, "system", c.DefaultNamespace)
+}
+
+// bsEntityList is bsSystemList for any kind of entity in any namespace, e.g.: component
+func bsEntityList(ctx context.Context, c *backstage.Client, kind, namespace string) (map[string]CatalogEntry, error) {
+	s := make(map[string]CatalogEntry)
 
-	if systems, _, err := c.Catalog.Entities.List(context.Background(), &backstage.ListEntityOptions{Filters: []string{"kind=system"}}); err != nil {
-		slog.Error("Failed to get System List from Backstage", slog.Any("Error", err))
-		s = append(s, "")
+	filter := "kind=" + kind + ",metadata.namespace=" + namespace
+	if entities, _, err := c.Catalog.Entities.List(ctx, &backstage.ListEntityOptions{Filters: []string{filter}}); err != nil {
+		slog.Error("Failed to get Entity List from Backstage", slog.String("Kind", kind), slog.Any("Error", err))
 		return s, err
 	} else {
-		for _, e := range systems {
-			s = append(s, e.Metadata.Name)
+		for _, e := range entities {
+			s[e.Metadata.Name] = bsEntry(e, namespace, c.DefaultNamespace)
 		}
-		slog.Debug("System List Found", slog.Any("Systems", s))
+		slog.Debug("Entity List Found", slog.String("Kind", kind), slog.Int("Count", len(s)))
 		return s, err
 	}
 }
+
+// bsEntry is what Verificat needs from a Backstage entity.
+// The namespace is where the entity was found, since Backstage can leave it out.
+func bsEntry(e backstage.Entity, namespace, defaultNamespace string) CatalogEntry {
+	ref := EntityRef{Kind: strings.ToLower(e.Kind), Namespace: namespace, Name: e.Metadata.Name}
+	owner, _ := e.Spec["owner"].(string)
+
+	// A Component names its System as a ref, e.g.: admin or system:default/admin
+	var system string
+	if s, ok := e.Spec["system"].(string); ok {
+		if sref, err := ParseEntityRef(s); err == nil {
+			system = sref.Name
+		}
+	}
+
+	return CatalogEntry{
+		Service: ref.Key(defaultNamespace),
+		Name:    e.Metadata.Name,
+		Kind:    ref.Kind,
+		Owner:   owner,
+		System:  system,
+		Labels:  e.Metadata.Labels,
+		Repos:   bsRepos(e.Metadata.Annotations),
+	}
+}
+
+// bsRepos reads the GitHub repo from an entity's project-slug annotation.
+// A missing or malformed annotation means no repos, and the default is used.
+func bsRepos(annotations map[string]string) []RepoRef {
+	slug, ok := annotations[projectSlug]
+	if !ok {
+		return nil
+	}
+
+	ref, err := ParseRepoRef(slug)
+	if err != nil {
+		slog.Warn("Bad Annotation", slog.String("Key", projectSlug), slog.Any("Error", err))
+		return nil
+	}
+	return []RepoRef{ref}
+}
@@ -1,98 +1,192 @@
 package verificat
 
-/*
-Integration Testing for Backstage
-const (
-	backstageURL = "https://backstage.rainbowq.co"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
 )
-*/
 
-/* DISABLED UNTIL BACKSTAGE WORKS
+// mockSystems are the Systems in the mock Backstage catalog, by namespace.
+var mockSystems = map[string][]string{
+	"default": {
+		mockEntity("System", "admin", "code-owners-admin", "maroda/admin-api", `{"tier": "1"}`, ""),
+		mockSystem("core", "code-owners-core", ""),
+		mockSystem("ad-server", "code-owners-wasp", ""),
+	},
+	"payments": {mockSystem("ledger", "code-owners-ledger", "maroda/ledger@develop")},
+}
+
+// mockComponents are the Components in the mock Backstage catalog, by namespace.
+// admin-web is part of the admin System.
+var mockComponents = map[string][]string{
+	"default": {mockEntity("Component", "admin-web", "code-owners-admin", "", `{"tier": "2"}`, `"system": "admin"`)},
+}
+
+// mockAPIs are the APIs in the mock Backstage catalog, by namespace.
+var mockAPIs = map[string][]string{
+	"payments": {mockEntity("API", "ledger-api", "code-owners-ledger", "", "{}", `"type": "openapi"`)},
+}
+
+func mockSystem(name, owner, slug string) string {
+	return mockEntity("System", name, owner, slug, "{}", "")
+}
+
+func mockEntity(kind, name, owner, slug, labels, spec string) string {
+	annotations := "{}"
+	if slug != "" {
+		annotations = fmt.Sprintf(`{%q: %q}`, projectSlug, slug)
+	}
+	if spec != "" {
+		spec = ", " + spec
+	}
+	return fmt.Sprintf(`{"apiVersion": "backstage.io/v1alpha1", "kind": %q,
+		"metadata": {"name": %q, "labels": %s, "annotations": %s}, "spec": {"owner": %q%s}}`, kind, name, labels, annotations, owner, spec)
+}
+
+// makeMockBackstage is a stand-in for the Backstage catalog API.
+// With a token, every request without it as a bearer token is refused.
+func makeMockBackstage(token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		// List: /api/catalog/entities?filter=kind=system,metadata.namespace=default,metadata.name=admin
+		// The name is only given when a single entity is read.
+		if r.URL.Path != "/api/catalog/entities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		filter := make(map[string]string)
+		for _, cond := range strings.Split(r.URL.Query().Get("filter"), ",") {
+			k, v, _ := strings.Cut(cond, "=")
+			filter[k] = v
+		}
+
+		byKind := map[string]map[string][]string{"system": mockSystems, "component": mockComponents, "api": mockAPIs}
+		var entities []string
+		for _, e := range byKind[filter["kind"]][filter["metadata.namespace"]] {
+			if name, ok := filter["metadata.name"]; !ok || strings.Contains(e, fmt.Sprintf(`"name": %q`, name)) {
+				entities = append(entities, e)
+			}
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(entities, ","))
+	}))
+}
 
-// TODO: A unit test version of this could use a fake BSSE object.
-//
 // Can we see match elements of each service entity?
 func TestReadSystemBS(t *testing.T) {
-	t.Run("Integration: reads Backstage catalog and matches system names", func(t *testing.T) {
-		c, err := backstage.NewClient(backstageURL, "default", nil)
+	bs := makeMockBackstage("")
+	defer bs.Close()
+
+	t.Run("reads Backstage catalog and matches system names", func(t *testing.T) {
+		c, err := backstage.NewClient(bs.URL, "default", nil)
 		assertError(t, err, nil)
 
 		readTests := []struct {
 			Name    string
 			Service string
 			Expect  string
-			Client  *backstage.Client
 		}{
-			{"Admin", "admin", "code-owners-admin", c},
-			{"Core", "core", "code-owners-core", c},
-			{"AdServer", "ad-server", "code-owners-wasp", c},
+			{"Admin", "admin", "code-owners-admin"},
+			{"Core", "core", "code-owners-core"},
+			{"AdServer", "ad-server", "code-owners-wasp"},
 		}
 
 		for _, tt := range readTests {
-			got, service, err := ReadSystemBS(tt.Service, c)
-			want := tt.Expect
-			assertError(t, err, nil)
-			if diff := cmp.Diff(got, want); diff != "" {
-				t.Error(diff)
-				t.Errorf("For '%v' the service looks like\n: %v", tt.Service, service)
-			}
+			t.Run(tt.Name, func(t *testing.T) {
+				got, service, err := ReadSystemBS(context.Background(), tt.Service, c)
+				assertError(t, err, nil)
+				if diff := cmp.Diff(got, tt.Expect); diff != "" {
+					t.Error(diff)
+					t.Errorf("For '%v' the service looks like\n: %v", tt.Service, service)
+				}
+			})
 		}
 	})
 
 	// Test that the SystemNotRecognized error is thrown
-	t.Run("Integration: Handles only Systems it knows about", func(t *testing.T) {
-		c, err := backstage.NewClient(backstageURL, "default", nil)
+	t.Run("handles only Systems it knows about", func(t *testing.T) {
+		c, err := backstage.NewClient(bs.URL, "default", nil)
 		assertError(t, err, nil)
 
-		readTests := []struct {
-			Name    string
-			Service string
-			Expect  string
-			Client  *backstage.Client
-		}{
-			{"Core-App", "core-app", "", c},
-			{"Prince", "Revolution", "", c},
-			{"Rubidium-Strontium", "Isochron", "", c},
-		}
-
-		for _, tt := range readTests {
-			got, service, err := ReadSystemBS(tt.Service, c)
-			want := tt.Expect
+		for _, service := range []string{"core-app", "Revolution", "ledger"} {
+			got, _, err := ReadSystemBS(context.Background(), service, c)
 			assertError(t, err, SystemNotRecognized)
-			if diff := cmp.Diff(got, want); diff != "" {
-				t.Error(diff)
-				t.Errorf("For '%v' the service looks like\n: %v", tt.Service, service)
-			}
+			assertString(t, got, "")
 		}
 	})
+
+	t.Run("reads Systems in another namespace", func(t *testing.T) {
+		c, err := backstage.NewClient(bs.URL, "payments", nil)
+		assertError(t, err, nil)
+
+		got, _, err := ReadSystemBS(context.Background(), "ledger", c)
+		assertError(t, err, nil)
+		assertString(t, got, "code-owners-ledger")
+	})
 }
 
-// Integration: Backstage POST endpoint
-func TestStoreIDs(t *testing.T) {
-	store := StubServiceStore{
-		map[string]int{},
-		nil, nil,
-	}
-	server := NewVerificationServ(&store)
+// Every System is listed with what the catalog knows about it
+func TestBsSystemList(t *testing.T) {
+	bs := makeMockBackstage("")
+	defer bs.Close()
 
-	// Use a real service - like /verificat/ - to check readiness with the Backstage API
-	t.Run("Integration: Backstage returns 200 on POST", func(t *testing.T) {
-		service := "verificat"
-		request := newPostIDReq(service)
-		response := httptest.NewRecorder()
+	c, err := backstage.NewClient(bs.URL, "default", nil)
+	assertError(t, err, nil)
 
-		server.ServeHTTP(response, request)
+	got, err := bsSystemList(context.Background(), c)
+	assertError(t, err, nil)
+	assertIDEquals(t, len(got), 3)
 
-		assertStatus(t, response.Code, http.StatusAccepted)
+	admin := got["admin"]
+	assertString(t, admin.Kind, "system")
+	assertString(t, admin.Owner, "code-owners-admin")
+	assertString(t, admin.Labels["tier"], "1")
+	assertIDEquals(t, len(admin.Repos), 1)
+	assertString(t, admin.Repos[0].String(), "maroda/admin-api")
+}
 
-		if len(store.verifyCalls) != 1 {
-			t.Errorf("got %d calls to TriggerID want %d", len(store.verifyCalls), 1)
-		}
+// Any kind of entity can be read in any namespace
+func TestReadEntityBS(t *testing.T) {
+	bs := makeMockBackstage("")
+	defer bs.Close()
 
-		if store.verifyCalls[0] != service {
-			t.Errorf("did not store correct service got %q want %q", store.verifyCalls[0], service)
-		}
-	})
+	c, err := backstage.NewClient(bs.URL, "default", nil)
+	assertError(t, err, nil)
+
+	got, err := ReadEntityBS(context.Background(), EntityRef{Kind: "component", Namespace: "default", Name: "admin-web"}, c)
+	assertError(t, err, nil)
+	assertString(t, got.Spec["system"].(string), "admin")
+
+	entry := bsEntry(*got, "default", "default")
+	assertString(t, entry.Service, "component:default/admin-web")
+	assertString(t, entry.System, "admin")
+
+	_, err = ReadEntityBS(context.Background(), EntityRef{Kind: "api", Namespace: "default", Name: "ledger-api"}, c)
+	assertError(t, err, SystemNotRecognized)
 }
 
-*/
+// The project-slug annotation names the repo for a System
+func TestBsRepos(t *testing.T) {
+	t.Run("reads the project-slug annotation", func(t *testing.T) {
+		got := bsRepos(map[string]string{projectSlug: "maroda/admin-api"})
+		assertIDEquals(t, len(got), 1)
+		assertString(t, got[0].String(), "maroda/admin-api")
+	})
+
+	t.Run("ignores a missing or malformed annotation", func(t *testing.T) {
+		assertIDEquals(t, len(bsRepos(nil)), 0)
+		assertIDEquals(t, len(bsRepos(map[string]string{projectSlug: "admin-api"})), 0)
+	})
+}
@@ -0,0 +1,164 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Catalog is a service catalog backend, it reads one Service and lists them all.
+// Backstage (SvcConfig) is the default, FileCat and RepoCat serve teams without it.
+type Catalog interface {
+	SvcCat
+	SvcLister
+}
+
+// CatalogConfig selects the service catalog backend in a Checklist.
+type CatalogConfig struct {
+	Type string            `yaml:"type"` // One of catalogTypes, defaults to backstage
+	Args map[string]string `yaml:"args"` // Parameters for the catalog type
+}
+
+// catalogBackend opens the Catalog for the Service in sc, which ReadSvc fills in.
+type catalogBackend func(sc *SvcConfig) (Catalog, error)
+
+// catalogFactory builds a catalogBackend from the args of a CatalogConfig,
+// along with the repos the Checklist declares for each Service, and the forges they're on.
+type catalogFactory func(args map[string]string, repos map[string][]RepoRef, forges Forges) (catalogBackend, error)
+
+// catalogTypes are the service catalogs a Checklist can read from.
+var catalogTypes = map[string]catalogFactory{
+	"backstage": newBackstageCatalog,
+	"file":      newFileCatalog,
+	"repo":      newRepoCatalog,
+}
+
+// newCatalogBackend validates a CatalogConfig, an empty Type is Backstage.
+func (cc CatalogConfig) newCatalogBackend(repos map[string][]RepoRef, forges Forges) (catalogBackend, error) {
+	kind := cc.Type
+	if kind == "" {
+		kind = "backstage"
+	}
+
+	factory, ok := catalogTypes[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, known types are %v", kind, slices.Sorted(maps.Keys(catalogTypes)))
+	}
+
+	backend, err := factory(cc.Args, repos, forges)
+	if err != nil {
+		return nil, fmt.Errorf("bad args for type %q, %v", kind, err)
+	}
+	return backend, nil
+}
+
+// newBackstageCatalog reads Services from Backstage.
+// It's configured by the environment when a Service is read, not by args:
+// BACKSTAGE is required, BACKSTAGE_NAMESPACE and BACKSTAGE_TOKEN are optional.
+func newBackstageCatalog(args map[string]string, _ map[string][]RepoRef, _ Forges) (catalogBackend, error) {
+	if err := checkArgs(args); err != nil {
+		return nil, err
+	}
+
+	return func(sc *SvcConfig) (Catalog, error) {
+		envVar := "BACKSTAGE"
+		url := fillEnvVar(envVar)
+		if url == "ENOENT" {
+			slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", url))
+			return nil, fmt.Errorf("%s is not set, there is no service catalog to read.", envVar)
+		}
+
+		// The namespace and token are optional, Backstage defaults to "default" and no auth.
+		sc.URL = url
+		sc.Namespace = os.Getenv("BACKSTAGE_NAMESPACE")
+		sc.Token = os.Getenv("BACKSTAGE_TOKEN")
+		return sc, nil
+	}, nil
+}
+
+// SvcLister enumerates the Services in a catalog, e.g.: every System in Backstage.
+type SvcLister interface {
+	ListSvc(ctx context.Context, kinds ...string) ([]CatalogEntry, error)
+}
+
+// CatalogEntry is what a catalog knows about one Service when listing them all.
+type CatalogEntry struct {
+	Service string // How it's known in the almanac, see EntityRef.Key
+	Name    string
+	Kind    string            // e.g.: system or component
+	Owner   string            // The owner according to the catalog
+	System  string            // The System a Component is part of, if any
+	Labels  map[string]string // Used by a Selector
+	Repos   []RepoRef         // From the catalog, when it knows
+}
+
+// ListSvc lists every entity of the given kinds from Backstage, system when none are given.
+// Only entities in the client's namespace are listed.
+// Entries are sorted by how they're known in the almanac, so a sweep always runs in the same order.
+func (sc *SvcConfig) ListSvc(ctx context.Context, kinds ...string) ([]CatalogEntry, error) {
+	if len(kinds) == 0 {
+		kinds = []string{"system"}
+	}
+
+	c, err := newBackstageClient(sc.URL, sc.Namespace, sc.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CatalogEntry
+	for _, kind := range kinds {
+		listed, err := bsEntityList(ctx, c, kind, c.DefaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range listed {
+			entries = append(entries, entry)
+		}
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// sortEntries sorts by how each entry is known in the almanac.
+func sortEntries(entries []CatalogEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Service < entries[j].Service })
+}
+
+// Selector limits which catalog entries are verified.
+// An empty Selector matches everything.
+type Selector struct {
+	Owner  string            // Only entries with this owner
+	Labels map[string]string // Only entries with all of these labels
+}
+
+// ParseSelector reads an owner and any number of labels written as key=value.
+func ParseSelector(owner string, labels []string) (Selector, error) {
+	sel := Selector{Owner: owner, Labels: make(map[string]string, len(labels))}
+	for _, l := range labels {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return sel, fmt.Errorf("label selector %q must be key=value", l)
+		}
+		sel.Labels[k] = v
+	}
+	return sel, nil
+}
+
+// Matches is true when the entry has the owner and every label of the Selector.
+func (s Selector) Matches(entry CatalogEntry) bool {
+	if s.Owner != "" && s.Owner != entry.Owner {
+		return false
+	}
+	for k, v := range s.Labels {
+		if entry.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package verificat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSvcConfig_ListSvc(t *testing.T) {
+	bs := makeMockBackstage("")
+	defer bs.Close()
+
+	names := func(entries []CatalogEntry) []string {
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name)
+		}
+		return got
+	}
+
+	t.Run("lists Systems by default, sorted by name", func(t *testing.T) {
+		sc := &SvcConfig{URL: bs.URL}
+		got, err := sc.ListSvc(context.Background())
+		assertError(t, err, nil)
+		if diff := cmp.Diff(names(got), []string{"ad-server", "admin", "core"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("lists Components when asked", func(t *testing.T) {
+		sc := &SvcConfig{URL: bs.URL}
+		got, err := sc.ListSvc(context.Background(), "system", "component")
+		assertError(t, err, nil)
+		assertIDEquals(t, len(got), 4)
+		assertString(t, got[2].Kind, "component")
+	})
+
+	t.Run("returns an error when Backstage can't be read", func(t *testing.T) {
+		sc := &SvcConfig{URL: "http://127.0.0.1:1"}
+		_, err := sc.ListSvc(context.Background())
+		assertHasError(t, err)
+	})
+}
+
+func TestSelector_Matches(t *testing.T) {
+	entry := CatalogEntry{Name: "admin", Owner: "code-owners-admin", Labels: map[string]string{"tier": "1", "team": "sre"}}
+
+	selectorTests := []struct {
+		Name   string
+		Owner  string
+		Labels []string
+		Expect bool
+	}{
+		{"Empty", "", nil, true},
+		{"Owner", "code-owners-admin", nil, true},
+		{"OtherOwner", "code-owners-core", nil, false},
+		{"Label", "", []string{"tier=1"}, true},
+		{"EveryLabel", "code-owners-admin", []string{"tier=1", "team=sre"}, true},
+		{"OneLabelDiffers", "", []string{"tier=1", "team=web"}, false},
+		{"MissingLabel", "", []string{"region=us"}, false},
+	}
+
+	for _, tt := range selectorTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			sel, err := ParseSelector(tt.Owner, tt.Labels)
+			assertNoError(t, err)
+			assertBool(t, sel.Matches(entry), tt.Expect)
+		})
+	}
+
+	t.Run("a label must be key=value", func(t *testing.T) {
+		_, err := ParseSelector("", []string{"tier"})
+		assertHasError(t, err)
+	})
+}
@@ -0,0 +1,349 @@
+package verificat
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Checklist is the declarative Production Readiness Checklist.
+// It is read from a YAML or JSON file at startup (JSON is valid YAML),
+// so the Checks that make up a run can change without a new build.
+type Checklist struct {
+	Checks     []ChecklistItem          `yaml:"checks"`
+	Scoring    ScoringConfig            `yaml:"scoring"`
+	Objectives Objectives               `yaml:"objectives"`
+	Services   map[string]ServiceConfig `yaml:"services"`
+	Catalog    CatalogConfig            `yaml:"catalog"`
+	Owners     OwnerConfig              `yaml:"owners"`
+	Forges     map[string]ForgeConfig   `yaml:"forges"`
+
+	registry *Registry            // Built from Checks once they are validated
+	policy   ScoringPolicy        // Built from Scoring once it is validated
+	repoOf   map[string][]RepoRef // Built from Services once they are validated
+	catalog  catalogBackend       // Built from Catalog once it is validated
+	forges   Forges               // Built from Forges once they are validated
+}
+
+// ChecklistItem declares one Check: which built-in type runs,
+// how it is described and tagged, and the arguments it is given.
+type ChecklistItem struct {
+	ID          string            `yaml:"id"`          // Unique name for this item, e.g.: owner
+	Type        string            `yaml:"type"`        // The built-in Check that runs, see checkTypes
+	Description string            `yaml:"description"` // Optional, defaults to the built-in description
+	Weight      *int              `yaml:"weight"`      // Optional, defaults to 1, an explicit 0 is kept
+	Required    bool              `yaml:"required"`    // A failure makes the service not-ready
+	Timeout     time.Duration     `yaml:"timeout"`     // Optional, e.g.: 5s, defaults to defaultCheckTimeout
+	Skip        []string          `yaml:"skip"`        // Services this Check doesn't apply to
+	Principles  []Principle       `yaml:"principles"`  // Optional, defaults to the built-in principles
+	Args        map[string]string `yaml:"args"`        // Parameters for the Check type
+}
+
+// ServiceConfig is what the Checklist knows about a single Service.
+type ServiceConfig struct {
+	Tier  string   `yaml:"tier"`  // Selects the Objective this Service must meet
+	Repos []string `yaml:"repos"` // The repos it lives in, e.g.: maroda/verificat@main or gitlab:platform/infra/admin
+}
+
+// ownerMapped is a Check that compares owners,
+// it's given the Checklist's owner mapping table once the Checklist is built.
+type ownerMapped interface {
+	useOwners(on *OwnerNormalizer)
+}
+
+// forgeMapped is a Check that reads repos,
+// it's given the Checklist's forges once the Checklist is built.
+type forgeMapped interface {
+	useForges(fs Forges)
+}
+
+// checkFactory builds a Check from the args of a ChecklistItem.
+type checkFactory func(args map[string]string) (Check, error)
+
+// checkTypes are the built-in Checks a ChecklistItem can use as its Type.
+var checkTypes = map[string]checkFactory{
+	"owner":     newOwnerCheck,
+	"consensus": newConsensusCheck,
+}
+
+// DefaultChecklist is used when no checklist file is configured.
+// It matches what Verificat has always run: the Owner check.
+func DefaultChecklist() *Checklist {
+	cl := &Checklist{
+		Checks: []ChecklistItem{
+			{ID: "owner", Type: "owner", Required: true},
+		},
+	}
+	if err := cl.build(); err != nil {
+		slog.Error("Default Checklist is invalid", slog.Any("Error", err))
+	}
+	return cl
+}
+
+// LoadChecklist reads and validates a checklist file.
+func LoadChecklist(path string) (*Checklist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading checklist file %s, %v", path, err)
+	}
+
+	cl, err := ParseChecklist(data)
+	if err != nil {
+		return nil, fmt.Errorf("problem loading checklist file %s, %w", path, err)
+	}
+
+	slog.Info("Checklist Loaded", slog.String("File", path), slog.Int("Checks", len(cl.Checks)))
+	return cl, nil
+}
+
+// ParseChecklist decodes a YAML or JSON checklist and validates it.
+// Unknown fields are refused so that typos don't silently disable a Check.
+func ParseChecklist(data []byte) (*Checklist, error) {
+	cl := new(Checklist)
+	if err := yaml.UnmarshalStrict(data, cl); err != nil {
+		return nil, fmt.Errorf("problem parsing checklist, %v", err)
+	}
+
+	if err := cl.build(); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// Registry returns the Checks declared by this Checklist.
+func (cl *Checklist) Registry() *Registry {
+	return cl.registry
+}
+
+// Policy returns the ScoringPolicy selected by this Checklist.
+func (cl *Checklist) Policy() ScoringPolicy {
+	return cl.policy
+}
+
+// Repos returns the repos declared for each Service in this Checklist.
+func (cl *Checklist) Repos() map[string][]RepoRef {
+	return cl.repoOf
+}
+
+// ReadsGitHub is false when no repo is read from GitHub,
+// e.g. every repo is read from a local mirror declared as the github forge,
+// so GitHub credentials are only required when they're used.
+func (cl *Checklist) ReadsGitHub() bool {
+	if fc, ok := cl.forges["github"]; !ok || fc.Type == "github" {
+		return true
+	}
+	for _, fc := range cl.forges {
+		if fc.Type == "github" {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenCatalog returns the service catalog this Checklist reads from, for the Service in sc.
+// Reading the Service fills in sc.
+func (cl *Checklist) OpenCatalog(sc *SvcConfig) (Catalog, error) {
+	return cl.catalog(sc)
+}
+
+// build validates every item and creates the Registry.
+// All problems are reported together, each naming the item at fault.
+func (cl *Checklist) build() error {
+	var errs []error
+	registry := NewRegistry()
+
+	owners, err := newOwnerNormalizer(cl.Owners)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("owners: %w", err))
+	}
+
+	forges, err := newForges(cl.Forges)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("forges: %w", err))
+	}
+
+	if len(cl.Checks) == 0 {
+		errs = append(errs, errors.New("checklist has no checks"))
+	}
+
+	for i, item := range cl.Checks {
+		check, err := item.newCheck(owners, forges)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("checks[%d] (%s): %v", i, item.ID, err))
+			continue
+		}
+		if err := registry.Register(check); err != nil {
+			errs = append(errs, fmt.Errorf("checks[%d] (%s): %v", i, item.ID, err))
+		}
+	}
+
+	policy, err := newScoringPolicy(cl.Scoring)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("scoring: %v", err))
+	}
+
+	tierOf := make(map[string]string, len(cl.Services))
+	repoOf := make(map[string][]RepoRef, len(cl.Services))
+	for name, svc := range cl.Services {
+		tierOf[name] = svc.Tier
+		repos, err := parseRepoRefs(svc.Repos)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("services: %s: %v", name, err))
+		}
+		for _, ref := range repos {
+			if !forges.known(ref.Forge) {
+				errs = append(errs, fmt.Errorf("services: %s: unknown forge %q for %s, known forges are %v", name, ref.Forge, ref, forges.names()))
+			} else if strings.Contains(ref.Org, "/") && !forges.nests(ref.Forge) {
+				errs = append(errs, fmt.Errorf("services: %s: forge %q can't nest repos in groups, %s must be org/repo", name, ref.Forge, ref))
+			}
+		}
+		repoOf[name] = repos
+	}
+	if err := cl.Objectives.validate(tierOf); err != nil {
+		errs = append(errs, fmt.Errorf("objectives: %w", err))
+	}
+
+	catalog, err := cl.Catalog.newCatalogBackend(repoOf, forges)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("catalog: %v", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid checklist, %w", errors.Join(errs...))
+	}
+
+	cl.registry = registry
+	cl.policy = policy
+	cl.repoOf = repoOf
+	cl.catalog = catalog
+	cl.forges = forges
+	return nil
+}
+
+// newCheck validates a ChecklistItem and builds its Check.
+// A Check that compares owners is given the owner mapping table,
+// and one that reads repos is given the forges.
+func (item ChecklistItem) newCheck(owners *OwnerNormalizer, forges Forges) (Check, error) {
+	if item.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	factory, ok := checkTypes[item.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, known types are %v", item.Type, slices.Sorted(maps.Keys(checkTypes)))
+	}
+
+	if item.Weight != nil && *item.Weight < 0 {
+		return nil, fmt.Errorf("weight must not be negative, got %d", *item.Weight)
+	}
+
+	if item.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative, got %v", item.Timeout)
+	}
+
+	for _, p := range item.Principles {
+		if !slices.Contains(Principles, p) {
+			return nil, fmt.Errorf("unknown principle %q, known principles are %v", p, Principles)
+		}
+	}
+
+	check, err := factory(item.Args)
+	if err != nil {
+		return nil, fmt.Errorf("bad args for type %q, %v", item.Type, err)
+	}
+	if om, ok := check.(ownerMapped); ok {
+		om.useOwners(owners)
+	}
+	if fm, ok := check.(forgeMapped); ok {
+		fm.useForges(forges)
+	}
+
+	return &listedCheck{Check: check, item: item}, nil
+}
+
+// checkArgs refuses any args a Check type doesn't understand.
+func checkArgs(args map[string]string, known ...string) error {
+	for k := range args {
+		if !slices.Contains(known, k) {
+			return fmt.Errorf("unknown arg %q, known args are %v", k, known)
+		}
+	}
+	return nil
+}
+
+// listedCheck is a built-in Check as declared by a ChecklistItem,
+// which can override the ID, Description, and Principles.
+type listedCheck struct {
+	Check
+	item ChecklistItem
+}
+
+func (lc *listedCheck) ID() string { return lc.item.ID }
+
+func (lc *listedCheck) Description() string {
+	if lc.item.Description != "" {
+		return lc.item.Description
+	}
+	return lc.Check.Description()
+}
+
+func (lc *listedCheck) Principles() []Principle {
+	if len(lc.item.Principles) > 0 {
+		return lc.item.Principles
+	}
+	return lc.Check.Principles()
+}
+
+func (lc *listedCheck) Weight() int {
+	if lc.item.Weight != nil {
+		return *lc.item.Weight
+	}
+	return 1
+}
+
+func (lc *listedCheck) Required() bool { return lc.item.Required }
+
+func (lc *listedCheck) Skips(sc *SvcConfig) (string, bool) {
+	if slices.Contains(lc.item.Skip, sc.entityName()) {
+		return "listed in skip for this check", true
+	}
+	return "", false
+}
+
+func (lc *listedCheck) Timeout() time.Duration {
+	if lc.item.Timeout > 0 {
+		return lc.item.Timeout
+	}
+	return defaultCheckTimeout
+}
+
+// checkWeight is the Weight of a Check, 1 unless it declares otherwise.
+func checkWeight(c Check) int {
+	if w, ok := c.(interface{ Weight() int }); ok {
+		return w.Weight()
+	}
+	return 1
+}
+
+// checkTimeout is how long a Check may run, defaultCheckTimeout unless it declares otherwise.
+func checkTimeout(c Check) time.Duration {
+	if t, ok := c.(interface{ Timeout() time.Duration }); ok {
+		return t.Timeout()
+	}
+	return defaultCheckTimeout
+}
+
+// checkRequired is true if the Check is part of the Required baseline.
+func checkRequired(c Check) bool {
+	if r, ok := c.(interface{ Required() bool }); ok {
+		return r.Required()
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package verificat
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseChecklist(t *testing.T) {
+	t.Run("parses a YAML checklist", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner-docs
+    type: owner
+    description: CODEOWNERS lives in docs
+    weight: 3
+    principles: [documentation]
+    args:
+      path: /main/docs/CODEOWNERS
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		got := cl.Registry().Info()
+		want := []CheckInfo{{
+			ID:          "owner-docs",
+			Description: "CODEOWNERS lives in docs",
+			Principles:  []Principle{PrincipleDocs},
+			Weight:      3,
+		}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+
+		// The args reach the built-in check
+		oc := cl.Registry().Checks()[0].(*listedCheck).Check.(*OwnerCheck)
+		assertString(t, oc.Path, "/main/docs/CODEOWNERS")
+		assertString(t, oc.API, ghAPI)
+	})
+
+	t.Run("selects a scoring policy", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner
+    type: owner
+scoring:
+  policy: capped
+  caps:
+    documentation: 2
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+		assertString(t, cl.Policy().Name(), "capped")
+	})
+
+	t.Run("assigns service tiers", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner
+    type: owner
+objectives:
+  defaultTier: tier-3
+  tiers:
+    tier-1: {minScore: 97, ready: true}
+    tier-3: {minScore: 90}
+services:
+  admin:
+    tier: tier-1
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+		assertString(t, cl.Objectives.Tier("admin"), "tier-1")
+		assertString(t, cl.Objectives.Tier("core"), "tier-3")
+	})
+
+	t.Run("maps services to their repos", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner
+    type: owner
+services:
+  admin:
+    repos: [maroda/admin-api@develop, maroda/admin-web]
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		got := cl.Repos()["admin"]
+		assertIDEquals(t, len(got), 2)
+		assertString(t, got[0].String(), "maroda/admin-api@develop")
+		assertIDEquals(t, len(cl.Repos()["core"]), 0)
+	})
+
+	t.Run("sets a timeout for each check", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner
+    type: owner
+    timeout: 3s
+  - id: quick
+    type: owner
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		checks := cl.Registry().Checks()
+		if got := checkTimeout(checks[0]); got != 3*time.Second {
+			t.Errorf("got timeout %v want %v", got, 3*time.Second)
+		}
+		if got := checkTimeout(checks[1]); got != defaultCheckTimeout {
+			t.Errorf("got timeout %v want %v", got, defaultCheckTimeout)
+		}
+	})
+
+	t.Run("lists services a check is skipped for", func(t *testing.T) {
+		data := []byte(`{checks: [{id: owner, type: owner, skip: [legacy]}]}`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		check := cl.Registry().Checks()[0]
+		_, skip := checkSkips(check, &SvcConfig{Service: "legacy"})
+		assertBool(t, skip, true)
+		_, skip = checkSkips(check, &SvcConfig{Service: "admin"})
+		assertBool(t, skip, false)
+	})
+
+	t.Run("gives the owner mapping table to the owner check", func(t *testing.T) {
+		data := []byte(`
+checks:
+  - id: owner
+    type: owner
+owners:
+  aliases:
+    platform-team: ["@maroda/platform"]
+`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		oc := cl.Registry().Checks()[0].(*listedCheck).Check.(*OwnerCheck)
+		assertString(t, oc.Owners.Normalize("@maroda/platform", "maroda"), "platform-team")
+	})
+
+	t.Run("selects the service catalog", func(t *testing.T) {
+		catalogTests := []struct {
+			Name   string
+			Data   string
+			Expect Catalog
+		}{
+			{"Default", `{checks: [{id: owner, type: owner}]}`, &SvcConfig{}},
+			{"File", `{checks: [{id: owner, type: owner}], catalog: {type: file, args: {path: services.yaml}}}`, &FileCat{}},
+			{"Repo", `{checks: [{id: owner, type: owner}], catalog: {type: repo}}`, &RepoCat{}},
+		}
+
+		t.Setenv("BACKSTAGE", "http://backstage.example.com")
+		for _, tt := range catalogTests {
+			t.Run(tt.Name, func(t *testing.T) {
+				cl, err := ParseChecklist([]byte(tt.Data))
+				assertNoError(t, err)
+
+				got, err := cl.OpenCatalog(&SvcConfig{Service: "admin"})
+				assertNoError(t, err)
+				if reflect.TypeOf(got) != reflect.TypeOf(tt.Expect) {
+					t.Errorf("got catalog %T want %T", got, tt.Expect)
+				}
+			})
+		}
+	})
+
+	t.Run("parses a JSON checklist", func(t *testing.T) {
+		data := []byte(`{"checks": [{"id": "owner", "type": "owner"}]}`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+
+		got := cl.Registry().Info()[0]
+		assertString(t, got.Description, (&OwnerCheck{}).Description())
+		assertIDEquals(t, got.Weight, 1)
+		assertString(t, cl.Policy().Name(), "golf")
+	})
+
+	t.Run("keeps a declared weight of zero", func(t *testing.T) {
+		data := []byte(`{"checks": [{"id": "owner", "type": "owner", "weight": 0}]}`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+		assertIDEquals(t, cl.Registry().Info()[0].Weight, 0)
+	})
+
+	t.Run("reads repos on a declared forge", func(t *testing.T) {
+		data := []byte(`{checks: [{id: owner, type: owner}], forges: {work: {type: gitlab, api: "https://gitlab.example.com/api/v4"}}, services: {admin: {repos: ["work:platform/infra/admin", "gitea:maroda/admin"]}}}`)
+		cl, err := ParseChecklist(data)
+		assertNoError(t, err)
+		assertString(t, cl.Repos()["admin"][0].String(), "work:platform/infra/admin")
+	})
+
+	// Every problem is reported, naming the item that caused it
+	validationTests := []struct {
+		Name   string
+		Data   string
+		Expect string
+	}{
+		{"Empty", `checks: []`, "checklist has no checks"},
+		{"NoID", `checks: [{type: owner}]`, "id is required"},
+		{"UnknownType", `checks: [{id: a, type: uptime}]`, `checks[0] (a): unknown type "uptime"`},
+		{"UnknownPrinciple", `checks: [{id: a, type: owner, principles: [speed]}]`, `unknown principle "speed"`},
+		{"NegativeWeight", `checks: [{id: a, type: owner, weight: -1}]`, "weight must not be negative"},
+		{"NegativeTimeout", `checks: [{id: a, type: owner, timeout: -1s}]`, "timeout must not be negative"},
+		{"BadTimeout", `checks: [{id: a, type: owner, timeout: soon}]`, "problem parsing checklist"},
+		{"UnknownArg", `checks: [{id: a, type: owner, args: {branch: main}}]`, `unknown arg "branch"`},
+		{"Duplicate", `checks: [{id: a, type: owner}, {id: a, type: owner}]`, `checks[1] (a): check "a" is already registered`},
+		{"UnknownField", `checks: [{id: a, type: owner, wieght: 2}]`, "field wieght not found"},
+		{"UnknownTier", `{checks: [{id: a, type: owner}], services: {admin: {tier: tier-1}}}`, `objectives: service "admin" tier "tier-1" has no objective`},
+		{"BadRepo", `{checks: [{id: a, type: owner}], services: {admin: {repos: [admin]}}}`, `services: admin: repo "admin" must be org/repo`},
+		{"UnknownForge", `{checks: [{id: a, type: owner}], services: {admin: {repos: ["work:platform/admin"]}}}`, `services: admin: unknown forge "work"`},
+		{"NestedOnDeclaredGitea", `{checks: [{id: a, type: owner}], forges: {work: {type: gitea}}, services: {admin: {repos: ["work:platform/infra/admin"]}}}`, `services: admin: forge "work" can't nest repos in groups`},
+		{"UnknownForgeType", `{checks: [{id: a, type: owner}], forges: {work: {type: sourcehut}}}`, `forges: work: unknown type "sourcehut"`},
+		{"UnknownCatalog", `{checks: [{id: a, type: owner}], catalog: {type: ldap}}`, `catalog: unknown type "ldap"`},
+		{"CatalogFileWithoutPath", `{checks: [{id: a, type: owner}], catalog: {type: file}}`, `catalog: bad args for type "file", path is required`},
+		{"CatalogBadArg", `{checks: [{id: a, type: owner}], catalog: {type: backstage, args: {url: x}}}`, `catalog: bad args for type "backstage", unknown arg "url"`},
+		{"OwnerAliasOfTwoTeams", `{checks: [{id: a, type: owner}], owners: {aliases: {a-team: [ops], b-team: [ops]}}}`, `owners: aliases: "ops" is an alias of both`},
+		{"UnknownPolicy", `{checks: [{id: a, type: owner}], scoring: {policy: bowling}}`, `scoring: unknown scoring policy "bowling"`},
+	}
+
+	for _, tt := range validationTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			_, err := ParseChecklist([]byte(tt.Data))
+			assertGotError(t, err)
+			if err != nil && !strings.Contains(err.Error(), tt.Expect) {
+				t.Errorf("error %q does not contain %q", err, tt.Expect)
+			}
+		})
+	}
+}
+
+func TestLoadChecklist(t *testing.T) {
+	t.Run("loads the example checklist", func(t *testing.T) {
+		cl, err := LoadChecklist("../checklist.yaml")
+		assertNoError(t, err)
+		assertIDEquals(t, len(cl.Registry().Checks()), 1)
+	})
+
+	t.Run("missing file returns an error", func(t *testing.T) {
+		_, err := LoadChecklist("testdata/nothing-here.yaml")
+		assertGotError(t, err)
+	})
+
+	t.Run("default checklist matches the example", func(t *testing.T) {
+		file, err := os.ReadFile("../checklist.yaml")
+		assertNoError(t, err)
+		cl, err := ParseChecklist(file)
+		assertNoError(t, err)
+
+		if diff := cmp.Diff(cl.Registry().Info(), DefaultChecklist().Registry().Info()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
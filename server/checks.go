@@ -0,0 +1,267 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Principle is one of the Eight Principles of Production Readiness.
+// Every Check is tagged with one or more of these.
+type Principle string
+
+const (
+	PrincipleStability   Principle = "stability"
+	PrincipleReliability Principle = "reliability"
+	PrincipleScalability Principle = "scalability"
+	PrinciplePerformance Principle = "performance"
+	PrincipleFaultTol    Principle = "fault-tolerance"
+	PrincipleCatastrophe Principle = "catastrophe-preparedness"
+	PrincipleMonitoring  Principle = "monitoring"
+	PrincipleDocs        Principle = "documentation"
+)
+
+// Principles lists all Eight Principles in the order they appear in the README.
+var Principles = []Principle{
+	PrincipleStability,
+	PrincipleReliability,
+	PrincipleScalability,
+	PrinciplePerformance,
+	PrincipleFaultTol,
+	PrincipleCatastrophe,
+	PrincipleMonitoring,
+	PrincipleDocs,
+}
+
+// defaultCheckTimeout is how long a Check may run when the checklist doesn't say.
+const defaultCheckTimeout = webTimeout
+
+// Check is a single item on the Production Readiness Checklist.
+// Run is given the catalog entry for the service being tested
+// and reports both Validation and Verification in its CheckResult.
+type Check interface {
+	ID() string
+	Description() string
+	Principles() []Principle
+	Run(ctx context.Context, sc *SvcConfig) *CheckResult
+}
+
+// Status is the outcome of a single Check.
+type Status string
+
+const (
+	StatusPass    Status = "pass"    // Validation and Verification succeeded
+	StatusFail    Status = "fail"    // Validation or Verification failed
+	StatusWaived  Status = "waived"  // Failed, but an active Waiver accepts it
+	StatusError   Status = "error"   // The Check couldn't run, e.g. GitHub is down
+	StatusSkipped Status = "skipped" // The Check doesn't apply to this Service
+)
+
+// CheckResult holds the answers for a single Check
+type CheckResult struct {
+	ID         string       // The Check that produced this result
+	Status     Status       // The outcome, see Status
+	Principles []Principle  // The Eight Principles this result counts toward
+	Required   bool         // Part of the Required baseline
+	Weight     int          // Used by some ScoringPolicy
+	Present    bool         // Validation: the catalog value is populated
+	Works      bool         // Verification: the catalog value matches reality
+	Expect     string       // The value found in the catalog
+	Reality    string       // The value found at the source of truth
+	Normalized *Comparison  `json:",omitempty"` // Expect and Reality as they were compared
+	Repos      []RepoOwners `json:",omitempty"` // Reality in each repo, for a Service with several or read at a commit
+	Location   string       `json:",omitempty"` // Where the source of truth was found
+	Reason     string       `json:",omitempty"` // Why the Check errored or was skipped
+	Findings   []string     `json:",omitempty"` // Problems noticed along the way, e.g.: malformed CODEOWNERS lines
+	Waiver     *Waiver      `json:",omitempty"` // The Waiver accepting this failure
+}
+
+// Comparison is Expect and Reality after a Check normalized them,
+// e.g.: group:default/admin-team and @maroda/admin-team are both admin-team.
+type Comparison struct {
+	Expect  string
+	Reality []string
+}
+
+// RepoOwners is who owns one of a Service's repos, and whether it matches the catalog.
+type RepoOwners struct {
+	Repo       RepoRef
+	Owners     []string // As written in the repo
+	Normalized []string // As compared
+	Works      bool
+	Location   string `json:",omitempty"`
+	Commit     string `json:",omitempty"` // The commit the repo was read at, from a local mirror or checkout
+}
+
+// Passed is true when both Validation and Verification succeeded.
+func (cr *CheckResult) Passed() bool {
+	return cr.Present && cr.Works
+}
+
+// Counts is true when the result should be scored.
+// A waived failure doesn't count against the Service,
+// and neither does a Check that errored or was skipped:
+// an upstream outage is not a readiness regression.
+func (cr *CheckResult) Counts() bool {
+	switch cr.Status {
+	case StatusWaived, StatusError, StatusSkipped:
+		return false
+	}
+	return true
+}
+
+// counted is every result that should be scored.
+func counted(results []*CheckResult) []*CheckResult {
+	var scored []*CheckResult
+	for _, result := range results {
+		if result.Counts() {
+			scored = append(scored, result)
+		}
+	}
+	return scored
+}
+
+// Verdicts are given to each run alongside the Score.
+// Any failed Required check makes the service not-ready, no matter the Score.
+const (
+	VerdictReady    = "ready"
+	VerdictNotReady = "not-ready"
+)
+
+// Verdict is ready unless a Required check did not pass.
+// A Required check that was waived, errored, or skipped doesn't make the service not-ready.
+func Verdict(results []*CheckResult) string {
+	for _, result := range counted(results) {
+		if result.Required && !result.Passed() {
+			return VerdictNotReady
+		}
+	}
+	return VerdictReady
+}
+
+// CheckInfo is the metadata for a registered Check, used by the API.
+type CheckInfo struct {
+	ID          string
+	Description string
+	Principles  []Principle
+	Weight      int
+	Required    bool
+}
+
+// Registry holds every Check that makes up a verification run.
+type Registry struct {
+	checks []Check
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a Check to the Registry.
+// Check IDs must be unique, a duplicate is refused.
+func (r *Registry) Register(c Check) error {
+	for _, rc := range r.checks {
+		if rc.ID() == c.ID() {
+			return fmt.Errorf("check %q is already registered", c.ID())
+		}
+	}
+	r.checks = append(r.checks, c)
+	slog.Debug("Check Registered", slog.String("ID", c.ID()))
+	return nil
+}
+
+// Checks returns the registered Checks in the order they were registered.
+func (r *Registry) Checks() []Check {
+	return r.checks
+}
+
+// IDs returns the ID of every registered Check, in the order they were registered.
+func (r *Registry) IDs() []string {
+	ids := make([]string, 0, len(r.checks))
+	for _, c := range r.checks {
+		ids = append(ids, c.ID())
+	}
+	return ids
+}
+
+// Info returns the metadata for all registered Checks.
+func (r *Registry) Info() []CheckInfo {
+	info := make([]CheckInfo, 0, len(r.checks))
+	for _, c := range r.checks {
+		info = append(info, CheckInfo{
+			ID:          c.ID(),
+			Description: c.Description(),
+			Principles:  c.Principles(),
+			Weight:      checkWeight(c),
+			Required:    checkRequired(c),
+		})
+	}
+	return info
+}
+
+// Run executes every registered Check concurrently against the same service.
+// Results are returned with indexes matching the order of Checks().
+// Each Check gets its own timeout, and all of them stop when ctx is cancelled.
+func (r *Registry) Run(ctx context.Context, sc *SvcConfig) []*CheckResult {
+	// ErrorGroup is used only for its WaitGroup,
+	// each Check reports failure in its CheckResult, not as an error.
+	egrp := new(errgroup.Group)
+	results := make([]*CheckResult, len(r.checks))
+
+	for i, c := range r.checks {
+		egrp.Go(func() error {
+			// A Check that doesn't apply to this Service isn't run at all
+			if reason, skip := checkSkips(c, sc); skip {
+				results[i] = &CheckResult{Status: StatusSkipped, Reason: reason}
+				results[i].fill(c)
+				return nil
+			}
+
+			cctx, cancel := context.WithTimeout(ctx, checkTimeout(c))
+			defer cancel()
+
+			result := c.Run(cctx, sc)
+
+			// Whatever the Check saw after its context ended can't be trusted,
+			// but a Check that passed saw everything it needed to
+			if err := cctx.Err(); err != nil && !result.Passed() {
+				slog.Warn("Check Interrupted", slog.String("ID", c.ID()), slog.String("Service", sc.Service), slog.Any("Error", err))
+				result.Status = StatusError
+				result.Reason = err.Error()
+			}
+			result.fill(c)
+			if result.Status == "" {
+				result.Status = StatusFail
+				if result.Passed() {
+					result.Status = StatusPass
+				}
+			}
+			results[i] = result
+			return nil
+		})
+	}
+
+	egrp.Wait()
+	return results
+}
+
+// fill sets the metadata on a result from the Check that produced it.
+func (cr *CheckResult) fill(c Check) {
+	cr.ID = c.ID()
+	cr.Principles = c.Principles()
+	cr.Required = checkRequired(c)
+	cr.Weight = checkWeight(c)
+}
+
+// checkSkips is true, with the reason, when a Check doesn't apply to the Service.
+func checkSkips(c Check, sc *SvcConfig) (string, bool) {
+	if s, ok := c.(interface {
+		Skips(sc *SvcConfig) (string, bool)
+	}); ok {
+		return s.Skips(sc)
+	}
+	return "", false
+}
@@ -0,0 +1,188 @@
+package verificat
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mockCheck returns a canned result, optionally after a delay
+type mockCheck struct {
+	id     string
+	delay  time.Duration
+	result CheckResult
+	calls  atomic.Int32
+}
+
+func (mc *mockCheck) ID() string              { return mc.id }
+func (mc *mockCheck) Description() string     { return "mock check " + mc.id }
+func (mc *mockCheck) Principles() []Principle { return []Principle{PrincipleStability} }
+
+// Run waits out the delay, unless the context ends first
+func (mc *mockCheck) Run(ctx context.Context, sc *SvcConfig) *CheckResult {
+	mc.calls.Add(1)
+	select {
+	case <-time.After(mc.delay):
+	case <-ctx.Done():
+	}
+	result := mc.result
+	return &result
+}
+
+// lateCheck cancels the run just as its Check returns
+type lateCheck struct {
+	*mockCheck
+	cancel context.CancelFunc
+}
+
+func (lc *lateCheck) Run(ctx context.Context, sc *SvcConfig) *CheckResult {
+	defer lc.cancel()
+	return lc.mockCheck.Run(ctx, sc)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	t.Run("registers checks in order", func(t *testing.T) {
+		r := NewRegistry()
+		assertError(t, r.Register(&mockCheck{id: "one"}), nil)
+		assertError(t, r.Register(&mockCheck{id: "two"}), nil)
+
+		got := r.Info()
+		want := []CheckInfo{
+			{ID: "one", Description: "mock check one", Principles: []Principle{PrincipleStability}, Weight: 1},
+			{ID: "two", Description: "mock check two", Principles: []Principle{PrincipleStability}, Weight: 1},
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("refuses duplicate IDs", func(t *testing.T) {
+		r := NewRegistry()
+		assertError(t, r.Register(&mockCheck{id: "one"}), nil)
+		assertGotError(t, r.Register(&mockCheck{id: "one"}))
+		assertIDEquals(t, len(r.Checks()), 1)
+	})
+}
+
+func TestVerdict(t *testing.T) {
+	verdictTests := []struct {
+		Name    string
+		Results []*CheckResult
+		Expect  string
+	}{
+		{"NoChecks", nil, VerdictReady},
+		{"OptionalFailure", []*CheckResult{{Present: true}}, VerdictReady},
+		{"RequiredPass", []*CheckResult{{Required: true, Present: true, Works: true}}, VerdictReady},
+		{"RequiredFailure", []*CheckResult{{Present: true, Works: true}, {Required: true, Present: true}}, VerdictNotReady},
+		{"RequiredError", []*CheckResult{{Required: true, Status: StatusError}}, VerdictReady},
+		{"RequiredSkipped", []*CheckResult{{Required: true, Status: StatusSkipped}}, VerdictReady},
+		{"RequiredWaived", []*CheckResult{{Required: true, Status: StatusWaived}}, VerdictReady},
+	}
+
+	for _, tt := range verdictTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assertString(t, Verdict(tt.Results), tt.Expect)
+		})
+	}
+}
+
+func TestRegistry_Run(t *testing.T) {
+	t.Run("runs every check concurrently", func(t *testing.T) {
+		delay := 50 * time.Millisecond
+		r := NewRegistry()
+		checks := []*mockCheck{
+			{id: "one", delay: delay, result: CheckResult{Present: true, Works: true}},
+			{id: "two", delay: delay, result: CheckResult{Present: true}},
+			{id: "three", delay: delay},
+		}
+		for _, c := range checks {
+			r.Register(c)
+		}
+
+		start := time.Now()
+		results := r.Run(context.Background(), &SvcConfig{Service: "admin"})
+		elapsed := time.Since(start)
+
+		// Run serially, these would take at least three times the delay
+		if elapsed >= 3*delay {
+			t.Errorf("checks did not run concurrently, took %v", elapsed)
+		}
+
+		for i, c := range checks {
+			assertIDEquals(t, int(c.calls.Load()), 1)
+			assertString(t, results[i].ID, c.id)
+		}
+		assertBool(t, results[0].Passed(), true)
+		assertBool(t, results[1].Passed(), false)
+		assertBool(t, results[2].Passed(), false)
+	})
+
+	t.Run("each check is stopped at its own timeout", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register(&listedCheck{
+			Check: &mockCheck{id: "slow", delay: time.Minute},
+			item:  ChecklistItem{ID: "slow", Timeout: 20 * time.Millisecond},
+		})
+		r.Register(&mockCheck{id: "fast", result: CheckResult{Present: true, Works: true}})
+
+		start := time.Now()
+		results := r.Run(context.Background(), &SvcConfig{Service: "admin"})
+
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("slow check was not stopped, took %v", elapsed)
+		}
+		assertString(t, string(results[0].Status), string(StatusError))
+		assertString(t, results[0].Reason, context.DeadlineExceeded.Error())
+		assertString(t, string(results[1].Status), string(StatusPass))
+	})
+
+	t.Run("a check that doesn't apply is skipped, not run", func(t *testing.T) {
+		skipped := &mockCheck{id: "owner"}
+		r := NewRegistry()
+		r.Register(&listedCheck{Check: skipped, item: ChecklistItem{ID: "owner", Skip: []string{"admin"}}})
+
+		results := r.Run(context.Background(), &SvcConfig{Service: "admin"})
+
+		assertIDEquals(t, int(skipped.calls.Load()), 0)
+		assertString(t, string(results[0].Status), string(StatusSkipped))
+		assertString(t, results[0].ID, "owner")
+		assertBool(t, results[0].Counts(), false)
+	})
+
+	t.Run("a check that passed before the run was cancelled still passes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := NewRegistry()
+		r.Register(&lateCheck{
+			mockCheck: &mockCheck{id: "owner", result: CheckResult{Present: true, Works: true}},
+			cancel:    cancel,
+		})
+		r.Register(&lateCheck{
+			mockCheck: &mockCheck{id: "docs", result: CheckResult{Present: true}},
+			cancel:    cancel,
+		})
+
+		results := r.Run(ctx, &SvcConfig{Service: "admin"})
+
+		assertString(t, string(results[0].Status), string(StatusPass))
+		assertString(t, string(results[1].Status), string(StatusError))
+	})
+
+	t.Run("cancelling the run stops every check", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register(&mockCheck{id: "one", delay: time.Minute})
+		r.Register(&mockCheck{id: "two", delay: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		r.Run(ctx, &SvcConfig{Service: "admin"})
+
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("checks were not cancelled, took %v", elapsed)
+		}
+	})
+}
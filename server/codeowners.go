@@ -0,0 +1,317 @@
+package verificat
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Codeowners is a parsed CODEOWNERS file.
+// Rules are kept in file order, because the last matching rule wins.
+// https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners
+//
+// GitLab and Gitea write CODEOWNERS their own way, see codeownersSyntax.
+type Codeowners struct {
+	Rules    []CodeownersRule
+	Findings []CodeownersFinding // Lines that were skipped, and why
+	syntax   *codeownersSyntax
+}
+
+// CodeownersRule is one line of CODEOWNERS: a pattern and who owns what it matches.
+// A rule with no Owners is valid, it leaves the matching paths unowned.
+type CodeownersRule struct {
+	Line    int      // Line number in the file, starting at 1
+	Section string   // The GitLab section the rule is in, e.g.: Backend
+	Pattern string   // As written, e.g.: /docs/ or *.go
+	Owners  []string // As written, e.g.: @org/team, @user, or user@example.com
+	match   *regexp.Regexp
+	negate  bool // Gitea: the rule is for every path the pattern doesn't match
+}
+
+// codeownersSyntax is how a forge writes CODEOWNERS.
+//
+//   - GitHub: gitignore style patterns, and the last matching rule wins.
+//   - GitLab: the same, split into [Section] headers which can name default owners
+//     for rules without any. The last matching rule in every section applies.
+//     https://docs.gitlab.com/user/project/codeowners/reference/
+//   - Gitea: patterns are regular expressions, ! in front negates one,
+//     and every matching rule applies.
+//     https://docs.gitea.com/usage/code-owners
+type codeownersSyntax struct {
+	Name     string
+	Sections bool           // [Section] headers, GitLab
+	Regexp   bool           // Patterns are regular expressions, Gitea
+	Owner    *regexp.Regexp // A valid @ owner, emails are valid everywhere
+	CatchAll []string       // Patterns that match every path
+}
+
+var (
+	githubCodeowners = &codeownersSyntax{Name: "github", Owner: ownerHandle, CatchAll: []string{"*", "**", "/**"}}
+	gitlabCodeowners = &codeownersSyntax{Name: "gitlab", Sections: true, Owner: gitlabHandle, CatchAll: []string{"*", "**", "/**"}}
+	giteaCodeowners  = &codeownersSyntax{Name: "gitea", Regexp: true, Owner: ownerHandle, CatchAll: []string{".*", "^.*$"}}
+)
+
+// gitlabSection is a section header, e.g.: ^[Docs][2] @maroda/docs
+// ^ makes the section optional, and [2] is how many approvals it needs.
+var gitlabSection = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?(.*)$`)
+
+// CodeownersFinding is a malformed line, which GitHub ignores too.
+type CodeownersFinding struct {
+	Line    int
+	Text    string
+	Problem string
+}
+
+func (cf CodeownersFinding) String() string {
+	return fmt.Sprintf("line %d: %s: %q", cf.Line, cf.Problem, cf.Text)
+}
+
+// Owners are @user, @org/team, or an email address.
+// GitLab groups can be nested, and @@role names everyone with a role, e.g.: @@maintainer
+var (
+	ownerHandle  = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[A-Za-z0-9._-]+)?$`)
+	gitlabHandle = regexp.MustCompile(`^@@?[A-Za-z0-9_][A-Za-z0-9_.-]*(?:/[A-Za-z0-9_.-]+)*$`)
+	ownerEmail   = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ParseCodeowners reads every rule from a GitHub CODEOWNERS file.
+// Malformed lines are skipped and reported as Findings, the rest still apply.
+func ParseCodeowners(data string) *Codeowners {
+	return parseCodeowners(data, githubCodeowners)
+}
+
+// parseCodeowners reads every rule from a CODEOWNERS file written in a forge's syntax.
+func parseCodeowners(data string, syntax *codeownersSyntax) *Codeowners {
+	co := &Codeowners{syntax: syntax}
+	var section string
+	var defaults []string
+
+	for i, line := range strings.Split(data, "\n") {
+		num := i + 1
+
+		// Anything after an unescaped # is a comment
+		text := strings.TrimSpace(stripComment(line))
+		if text == "" {
+			continue
+		}
+
+		// A GitLab section lasts until the next one
+		if m := gitlabSection.FindStringSubmatch(text); syntax.Sections && m != nil {
+			owners := strings.Fields(m[2])
+			if bad := badOwners(owners, syntax); len(bad) > 0 {
+				co.Findings = append(co.Findings, CodeownersFinding{Line: num, Text: text, Problem: fmt.Sprintf("invalid owner %s", strings.Join(bad, ", "))})
+				owners = nil
+			}
+			section, defaults = strings.TrimSpace(m[1]), owners
+			continue
+		}
+
+		fields := strings.Fields(text)
+		pattern, owners := fields[0], fields[1:]
+
+		rule := CodeownersRule{Line: num, Section: section, Pattern: pattern, Owners: owners}
+		if problem := rule.compile(syntax); problem != "" {
+			co.Findings = append(co.Findings, CodeownersFinding{Line: num, Text: text, Problem: problem})
+			continue
+		}
+
+		if bad := badOwners(owners, syntax); len(bad) > 0 {
+			co.Findings = append(co.Findings, CodeownersFinding{Line: num, Text: text, Problem: fmt.Sprintf("invalid owner %s", strings.Join(bad, ", "))})
+			continue
+		}
+		if len(rule.Owners) == 0 {
+			rule.Owners = defaults
+		}
+
+		co.Rules = append(co.Rules, rule)
+	}
+
+	return co
+}
+
+// compile checks the rule's pattern is valid for the syntax, and prepares it for matching.
+// A problem is reported instead of compiling an invalid pattern.
+func (cr *CodeownersRule) compile(syntax *codeownersSyntax) string {
+	if !syntax.Regexp {
+		if problem := checkPattern(cr.Pattern); problem != "" {
+			return problem
+		}
+		cr.match = patternRegexp(cr.Pattern)
+		return ""
+	}
+
+	expr, negate := strings.CutPrefix(cr.Pattern, "!")
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return "invalid regular expression"
+	}
+	cr.match, cr.negate = re, negate
+	return ""
+}
+
+// matches is true when the rule applies to a path.
+func (cr *CodeownersRule) matches(path string) bool {
+	return cr.match.MatchString(path) != cr.negate
+}
+
+// badOwners is every owner that isn't valid in the syntax.
+func badOwners(owners []string, syntax *codeownersSyntax) []string {
+	var bad []string
+	for _, o := range owners {
+		if !syntax.Owner.MatchString(o) && !ownerEmail.MatchString(o) {
+			bad = append(bad, o)
+		}
+	}
+	return bad
+}
+
+// Match returns the rule that owns a path in the repo, e.g.: docs/index.md
+// The last matching rule wins, nil means no rule matches.
+func (co *Codeowners) Match(path string) *CodeownersRule {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].matches(path) {
+			return &co.Rules[i]
+		}
+	}
+	return nil
+}
+
+// OwnersOf is every owner of a path in the repo.
+// That's the last matching rule, in every GitLab section, or every matching rule for Gitea.
+func (co *Codeowners) OwnersOf(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	return co.owners(func(cr *CodeownersRule) bool { return cr.matches(path) })
+}
+
+// CatchAll is the last rule matching every file, e.g.: * or /**
+// This is who owns the repo as a whole, nil if there isn't one.
+func (co *Codeowners) CatchAll() *CodeownersRule {
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].catchAll(co.syntax) {
+			return &co.Rules[i]
+		}
+	}
+	return nil
+}
+
+// CatchAllOwners is who owns the repo as a whole,
+// from the catch-all rules chosen the same way as OwnersOf.
+func (co *Codeowners) CatchAllOwners() []string {
+	return co.owners(func(cr *CodeownersRule) bool { return cr.catchAll(co.syntax) })
+}
+
+// catchAll is true for a rule whose pattern matches every file.
+func (cr *CodeownersRule) catchAll(syntax *codeownersSyntax) bool {
+	if syntax == nil {
+		syntax = githubCodeowners
+	}
+	return !cr.negate && slices.Contains(syntax.CatchAll, cr.Pattern)
+}
+
+// owners is every owner from the rules that apply, without repeats, in the order they're written.
+// Like Match, the last rule wins, unless the syntax applies more than one.
+// A rule that applies without owners leaves the path unowned, that's empty rather than nil.
+func (co *Codeowners) owners(applies func(*CodeownersRule) bool) []string {
+	every := co.syntax != nil && co.syntax.Regexp
+	var rules []*CodeownersRule
+	done := make(map[string]bool)
+
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		rule := &co.Rules[i]
+		section := strings.ToLower(rule.Section) // GitLab sections with the same name are one
+		if done[section] || !applies(rule) {
+			continue
+		}
+		rules = append(rules, rule)
+		done[section] = !every
+	}
+	if rules == nil {
+		return nil
+	}
+
+	owners := []string{}
+	for _, rule := range slices.Backward(rules) {
+		for _, o := range rule.Owners {
+			if !slices.Contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// stripComment removes a trailing comment, leaving an escaped \# alone.
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// checkPattern reports gitignore syntax that CODEOWNERS does not support.
+func checkPattern(pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		return "negated patterns are not supported"
+	case strings.ContainsAny(pattern, "[]"):
+		return "character ranges are not supported"
+	case strings.HasPrefix(pattern, "@"):
+		return "missing pattern before owners"
+	}
+	return ""
+}
+
+// patternRegexp converts a gitignore style pattern to a regexp for repo paths.
+//
+//   - A leading /, or a / in the middle, anchors the pattern to the repo root,
+//     otherwise it matches at any depth.
+//   - A trailing / only matches a directory, so everything inside it.
+//   - * and ? don't cross a /, but ** does.
+//   - A pattern naming a directory matches everything inside it,
+//     unless its last part has a wildcard, e.g.: docs/* is only the files directly in docs.
+func patternRegexp(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case p[i] == '*':
+			re.WriteString("[^/]*")
+		case p[i] == '?':
+			re.WriteString("[^/]")
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(p[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+
+	last := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dirOnly:
+		re.WriteString("/.*")
+	case !strings.ContainsAny(last, "*?"):
+		re.WriteString("(?:/.*)?")
+	}
+	re.WriteString("$")
+
+	return regexp.MustCompile(re.String())
+}
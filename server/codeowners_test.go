@@ -0,0 +1,151 @@
+package verificat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const mockCodeowners = `# Default owners for everything in the repo
+*                 @maroda/admin @maroda/sre
+
+# Go files, anywhere
+*.go              @maroda/backend   # trailing comment
+
+/docs/            docs@example.com
+apps/*            @octocat
+**/logs           @maroda/observability
+/build/logs/
+!/vendor/         @maroda/admin
+/src/[a-z]*.js    @maroda/frontend
+/config/          maroda
+`
+
+func TestParseCodeowners(t *testing.T) {
+	co := ParseCodeowners(mockCodeowners)
+
+	t.Run("parses every valid rule in order", func(t *testing.T) {
+		var got []string
+		for _, r := range co.Rules {
+			got = append(got, r.Pattern)
+		}
+		want := []string{"*", "*.go", "/docs/", "apps/*", "**/logs", "/build/logs/"}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+		assertIDEquals(t, co.Rules[1].Line, 5)
+	})
+
+	t.Run("reports malformed lines as findings", func(t *testing.T) {
+		var got []int
+		for _, f := range co.Findings {
+			got = append(got, f.Line)
+		}
+		if diff := cmp.Diff(got, []int{11, 12, 13}); diff != "" {
+			t.Error(diff)
+		}
+		assertString(t, co.Findings[2].Problem, "invalid owner maroda")
+	})
+
+	t.Run("the catch-all rule owns the repo", func(t *testing.T) {
+		got := co.CatchAll().Owners
+		if diff := cmp.Diff(got, []string{"@maroda/admin", "@maroda/sre"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("an escaped # is part of the pattern", func(t *testing.T) {
+		got := ParseCodeowners(`\#notes @maroda/admin`)
+		assertIDEquals(t, len(got.Rules), 1)
+		assertIDEquals(t, len(got.OwnersOf("#notes")), 1)
+	})
+}
+
+// The last matching rule wins
+func TestCodeowners_OwnersOf(t *testing.T) {
+	co := ParseCodeowners(mockCodeowners)
+
+	ownerTests := []struct {
+		Path   string
+		Expect []string
+	}{
+		{"README.md", []string{"@maroda/admin", "@maroda/sre"}},
+		{"main.go", []string{"@maroda/backend"}},
+		{"server/deep/server.go", []string{"@maroda/backend"}},
+		{"docs/index.md", []string{"docs@example.com"}},
+		{"docs/api/v1.go", []string{"docs@example.com"}},
+		{"apps/web.yaml", []string{"@octocat"}},
+		{"apps/web/config.yaml", []string{"@maroda/admin", "@maroda/sre"}},
+		{"server/logs/app.log", []string{"@maroda/observability"}},
+		{"build/logs/app.log", []string{}},
+	}
+
+	for _, tt := range ownerTests {
+		t.Run(tt.Path, func(t *testing.T) {
+			got := co.OwnersOf(tt.Path)
+			if diff := cmp.Diff(got, tt.Expect); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("no rule matches", func(t *testing.T) {
+		co := ParseCodeowners("/docs/ @maroda/docs\n")
+		if co.Match("main.go") != nil || co.CatchAll() != nil {
+			t.Errorf("expected no matching rule")
+		}
+	})
+}
+
+// The last matching rule in every section applies,
+// and a rule without owners takes its section's default owners
+func TestParseCodeowners_GitLab(t *testing.T) {
+	co := parseCodeowners(`* @platform/admin
+
+[Docs] @platform/infra/docs
+docs/
+*.md @writers
+
+^[Security][2] @@maintainer
+/auth/ @platform/security
+`, gitlabCodeowners)
+
+	assertIDEquals(t, len(co.Findings), 0)
+
+	ownerTests := []struct {
+		Path   string
+		Expect []string
+	}{
+		{"main.go", []string{"@platform/admin"}},
+		{"docs/index.md", []string{"@platform/admin", "@writers"}},
+		{"docs/api/v1.go", []string{"@platform/admin", "@platform/infra/docs"}},
+		{"auth/login.go", []string{"@platform/admin", "@platform/security"}},
+	}
+
+	for _, tt := range ownerTests {
+		t.Run(tt.Path, func(t *testing.T) {
+			if diff := cmp.Diff(co.OwnersOf(tt.Path), tt.Expect); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("sections are named case-insensitively", func(t *testing.T) {
+		co := parseCodeowners("[Docs]\ndocs/ @a\n[docs]\ndocs/ @b\n", gitlabCodeowners)
+		assertMultiString(t, co.OwnersOf("docs/x.md"), []string{"@b"})
+	})
+}
+
+// Patterns are regular expressions, ! negates one, and every matching rule applies
+func TestParseCodeowners_Gitea(t *testing.T) {
+	co := parseCodeowners(`.* @maroda/admin
+.*\.go$ @maroda/backend
+!vendor/.*\.go$ @maroda/backend-reviewers
+[ bad
+`, giteaCodeowners)
+
+	assertIDEquals(t, len(co.Findings), 1)
+	assertMultiString(t, co.CatchAllOwners(), []string{"@maroda/admin"})
+	assertMultiString(t, co.OwnersOf("server/main.go"), []string{"@maroda/admin", "@maroda/backend", "@maroda/backend-reviewers"})
+	assertMultiString(t, co.OwnersOf("vendor/x/lib.go"), []string{"@maroda/admin", "@maroda/backend"})
+}
@@ -0,0 +1,419 @@
+package verificat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// OwnerClaim is who one source says owns a Service.
+type OwnerClaim struct {
+	Source   string   // The OwnerSource that made the claim, e.g.: codeowners
+	Owners   []string // As written at the source, e.g.: @maroda/admin-team
+	Repo     RepoRef  // The repo the claim was found in, if any
+	Org      string   // The GitHub org the claim was found in, used to normalize Owners
+	Location string   // Where the claim was found
+	Commit   string   // The commit the repo was read at, when its forge reads commits, see commitReader
+	Findings []string // Problems noticed reading the source
+}
+
+// OwnerSource is anywhere the owner of a Service is declared.
+// Most sources make one claim, a source read from every repo makes one for each.
+// A source without an owner for the Service answers SourceNotFound,
+// any other error means the source couldn't be read.
+type OwnerSource interface {
+	Name() string
+	Claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error)
+}
+
+// ownerSources are the names a ConsensusCheck can be given in its sources arg.
+var ownerSources = []string{"catalog", "codeowners", "kube", "oncall"}
+
+const (
+	defaultOwnerLabel  = "owner"
+	defaultOncallField = "team"
+)
+
+// ConsensusCheck asks every OwnerSource who owns the Service,
+// and passes when they all name the same owner.
+// The first source listed is the reference the others are compared to.
+type ConsensusCheck struct {
+	Sources []OwnerSource
+	Owners  *OwnerNormalizer // The Checklist's owner mapping table, set by useOwners
+}
+
+// newConsensusCheck is the checkFactory for the "consensus" type.
+// The sources arg is a comma separated list of ownerSources, by default catalog,codeowners.
+// The GitHub args are the same as for the "owner" type, and are used by codeowners and kube.
+func newConsensusCheck(args map[string]string) (Check, error) {
+	if err := checkArgs(args, "sources", "domain", "api", "org", "path", "owns", "manifest", "label", "oncall", "field"); err != nil {
+		return nil, err
+	}
+
+	// GitHub is read the same way as the "owner" type reads it
+	github := make(map[string]string)
+	for _, k := range []string{"domain", "api", "org", "path", "owns"} {
+		if v, ok := args[k]; ok {
+			github[k] = v
+		}
+	}
+	oc, err := newOwnerCheck(github)
+	if err != nil {
+		return nil, err
+	}
+
+	names := "catalog,codeowners"
+	if v, ok := args["sources"]; ok {
+		names = v
+	}
+
+	cc := new(ConsensusCheck)
+	var seen []string
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if slices.Contains(seen, name) {
+			return nil, fmt.Errorf("source %q is listed twice", name)
+		}
+		seen = append(seen, name)
+
+		switch name {
+		case "catalog":
+			cc.Sources = append(cc.Sources, catalogOwners{})
+		case "codeowners":
+			cc.Sources = append(cc.Sources, codeownersOwners{oc.(*OwnerCheck)})
+		case "kube":
+			if args["manifest"] == "" {
+				return nil, errors.New("source kube needs the manifest arg, e.g.: kube/app.yaml")
+			}
+			ko := kubeOwners{OwnerCheck: oc.(*OwnerCheck), Manifest: args["manifest"], Label: defaultOwnerLabel}
+			if v, ok := args["label"]; ok {
+				ko.Label = v
+			}
+			cc.Sources = append(cc.Sources, ko)
+		case "oncall":
+			if !strings.Contains(args["oncall"], "{service}") {
+				return nil, errors.New("source oncall needs the oncall arg, a URL with {service} in it")
+			}
+			oo := oncallOwners{URL: args["oncall"], Field: defaultOncallField}
+			if v, ok := args["field"]; ok {
+				oo.Field = v
+			}
+			cc.Sources = append(cc.Sources, oo)
+		default:
+			return nil, fmt.Errorf("unknown source %q, known sources are %v", name, ownerSources)
+		}
+	}
+
+	if len(cc.Sources) < 2 {
+		return nil, fmt.Errorf("at least two sources are needed to agree, got %q", names)
+	}
+	return cc, nil
+}
+
+// useOwners is given the Checklist's owner mapping table when the Checklist is built.
+func (cc *ConsensusCheck) useOwners(on *OwnerNormalizer) {
+	cc.Owners = on
+}
+
+// useForges hands the Checklist's forges to every source that reads a repo.
+func (cc *ConsensusCheck) useForges(fs Forges) {
+	for _, source := range cc.Sources {
+		if fm, ok := source.(forgeMapped); ok {
+			fm.useForges(fs)
+		}
+	}
+}
+
+func (cc *ConsensusCheck) ID() string { return "owner-consensus" }
+
+func (cc *ConsensusCheck) Description() string {
+	return "Every source of ownership names the same owner"
+}
+
+func (cc *ConsensusCheck) Principles() []Principle {
+	return []Principle{PrincipleDocs, PrincipleCatastrophe}
+}
+
+// Run asks each source in turn who owns the Service.
+// Validation: every claim names an owner, one from each source and each repo it reads.
+// Verification: every claim names the owner the first one does.
+func (cc *ConsensusCheck) Run(ctx context.Context, sc *SvcConfig) *CheckResult {
+	var claims []*OwnerClaim
+	var findings, locations []string
+	present := true
+
+	for _, source := range cc.Sources {
+		sourceClaims, err := source.Claims(ctx, sc)
+		if err != nil && !errors.Is(err, SourceNotFound) {
+			// Without every source there's no consensus to find,
+			// this is an error with the source, not a failure of the service.
+			slog.Error("Cannot Read Owner Source", slog.String("Source", source.Name()), slog.Any("Error", err))
+			return &CheckResult{
+				Status: StatusError,
+				Expect: sc.Owner,
+				Reason: fmt.Sprintf("%s: %v", source.Name(), err),
+			}
+		}
+
+		for _, claim := range sourceClaims {
+			for _, f := range claim.Findings {
+				findings = append(findings, claim.Source+": "+f)
+			}
+			if claim.Location != "" {
+				locations = append(locations, claim.Location)
+			}
+			if len(claim.Owners) == 0 {
+				slog.Warn("No Owner Declared", slog.String("Service", sc.Service), slog.String("Source", claim.Source))
+				findings = append(findings, claim.Source+": no owner declared")
+				present = false
+				continue
+			}
+			claims = append(claims, claim)
+		}
+	}
+
+	// Every claim is normalized before comparing,
+	// each source shows as source=owner in Reality.
+	// A source outside GitHub, like the catalog, is normalized with the repo's org.
+	var org string
+	for _, claim := range claims {
+		if claim.Org != "" {
+			org = claim.Org
+			break
+		}
+	}
+	var reference []string
+	var reality, normalized []string
+	works := present
+	for i, claim := range claims {
+		owners := cc.normalize(claim, org)
+		for _, o := range claim.Owners {
+			reality = append(reality, claim.Source+"="+o)
+		}
+		for _, o := range owners {
+			normalized = append(normalized, claim.Source+"="+o)
+		}
+
+		if i == 0 {
+			reference = owners
+			continue
+		}
+		if !slices.ContainsFunc(owners, func(o string) bool { return slices.Contains(reference, o) }) {
+			works = false
+			slog.Warn("Owner Disagrees", slog.String("Service", sc.Service), slog.String("Source", claim.Source), slog.Any("Owners", owners), slog.Any("Reference", reference))
+			findings = append(findings, fmt.Sprintf("%s: %s disagrees with %s: %s",
+				claim.Source, strings.Join(claim.Owners, " "), claims[0].Source, strings.Join(claims[0].Owners, " ")))
+		}
+	}
+
+	var expect string
+	if len(claims) > 0 {
+		expect = claims[0].Source + "=" + strings.Join(claims[0].Owners, " ")
+	}
+
+	return &CheckResult{
+		Present:    present,
+		Works:      works,
+		Expect:     expect,
+		Reality:    strings.Join(reality, " "),
+		Normalized: &Comparison{Expect: strings.Join(reference, " "), Reality: normalized},
+		Location:   strings.Join(locations, " "),
+		Findings:   findings,
+	}
+}
+
+// normalize is every owner in a claim, as the owner mapping table names them.
+// The claim's own org is used when it has one.
+func (cc *ConsensusCheck) normalize(claim *OwnerClaim, org string) []string {
+	if claim.Org != "" {
+		org = claim.Org
+	}
+	var owners []string
+	for _, o := range claim.Owners {
+		owners = append(owners, cc.Owners.Normalize(o, org))
+	}
+	return owners
+}
+
+// catalogOwners is the Owner the service catalog has for the Service.
+type catalogOwners struct{}
+
+func (catalogOwners) Name() string { return "catalog" }
+
+func (catalogOwners) Claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error) {
+	claim := &OwnerClaim{Source: "catalog"}
+	if sc.Owner == "" {
+		return []*OwnerClaim{claim}, fmt.Errorf("%w: no owner in the catalog for %s", SourceNotFound, sc.Service)
+	}
+	claim.Owners = []string{sc.Owner}
+	return []*OwnerClaim{claim}, nil
+}
+
+// codeownersOwners is who CODEOWNERS names, read the same way as OwnerCheck.
+// Every repo of the Service makes its own claim, so each has to agree.
+// With more than one repo, each claim's Source names its repo, e.g.: codeowners(maroda/admin-web)
+type codeownersOwners struct {
+	*OwnerCheck
+}
+
+func (codeownersOwners) Name() string { return "codeowners" }
+
+func (co codeownersOwners) Claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error) {
+	claims, err := co.claims(ctx, sc)
+	if len(claims) > 1 {
+		for _, claim := range claims {
+			claim.Source = fmt.Sprintf("%s(%s)", claim.Source, claim.Repo)
+		}
+	}
+	return claims, err
+}
+
+// kubeOwners is the owner label on the Kubernetes manifest in the Service's repo.
+// Both the label on each resource and on its pod template are read.
+type kubeOwners struct {
+	*OwnerCheck        // Where the manifest is fetched
+	Manifest    string // The path in the repo, e.g.: kube/verificat-app.yaml
+	Label       string // The label naming the owner, e.g.: owner
+}
+
+func (kubeOwners) Name() string { return "kube" }
+
+func (ko kubeOwners) Claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error) {
+	ref := ko.repo(sc)
+	claim := &OwnerClaim{Source: "kube", Repo: ref, Org: ref.Org}
+
+	forge, err := ko.forge(ref)
+	if err != nil {
+		return []*OwnerClaim{claim}, err
+	}
+
+	read, commit, err := pinCommit(ctx, forge, ref)
+	if err != nil {
+		return []*OwnerClaim{claim}, err
+	}
+	claim.Commit = commit
+
+	body, location, err := forge.RepoFile(ctx, read, ko.Manifest)
+	if errors.Is(err, SourceNotFound) {
+		claim.Findings = append(claim.Findings, err.Error())
+	}
+	if err != nil {
+		return []*OwnerClaim{claim}, err
+	}
+	claim.Location = location
+
+	owners, err := manifestLabels(body, ko.Label)
+	if err != nil {
+		claim.Findings = append(claim.Findings, err.Error())
+	}
+	claim.Owners = owners
+	return []*OwnerClaim{claim}, nil
+}
+
+// kubeResource is the part of a Kubernetes resource that holds labels.
+type kubeResource struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+// manifestLabels is every value of a label in a multi-document manifest, without repeats.
+// Resources are read until one can't be parsed, which is returned as an error.
+func manifestLabels(data, label string) ([]string, error) {
+	var values []string
+	add := func(labels map[string]string) {
+		if v := labels[label]; v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	dec := yaml.NewDecoder(strings.NewReader(data))
+	for {
+		var res kubeResource
+		err := dec.Decode(&res)
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		if err != nil {
+			return values, fmt.Errorf("could not read manifest, %v", err)
+		}
+		add(res.Metadata.Labels)
+		add(res.Spec.Template.Metadata.Labels)
+	}
+}
+
+// oncallOwners is the team on call for the Service, from an on-call rota's API.
+// The URL is requested with {service} replaced, and Field is read from the JSON answer.
+// ONCALL_TOKEN is sent as a bearer token when it's set.
+type oncallOwners struct {
+	URL   string // e.g.: https://oncall.example.com/api/services/{service}
+	Field string // The JSON field naming the team, e.g.: team
+}
+
+func (oncallOwners) Name() string { return "oncall" }
+
+func (oo oncallOwners) Claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error) {
+	claim := &OwnerClaim{Source: "oncall"}
+	target := strings.ReplaceAll(oo.URL, "{service}", url.PathEscape(sc.entityName()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return []*OwnerClaim{claim}, err
+	}
+	req.Header.Add("Accept", "application/json")
+	if token := fillEnvVar("ONCALL_TOKEN"); token != "ENOENT" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: webTimeout}
+	r, err := client.Do(req)
+	if err != nil {
+		return []*OwnerClaim{claim}, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusNotFound {
+		return []*OwnerClaim{claim}, fmt.Errorf("%w: %s", SourceNotFound, target)
+	}
+	if r.StatusCode != http.StatusOK {
+		return []*OwnerClaim{claim}, fmt.Errorf("non 200 Status from %s: %d", target, r.StatusCode)
+	}
+
+	var answer map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		return []*OwnerClaim{claim}, fmt.Errorf("could not read rota from %s, %v", target, err)
+	}
+	claim.Location = target
+
+	// The team is a name, or a list of names
+	switch v := answer[oo.Field].(type) {
+	case string:
+		if v != "" {
+			claim.Owners = []string{v}
+		}
+	case []any:
+		for _, o := range v {
+			if s, ok := o.(string); ok && s != "" {
+				claim.Owners = append(claim.Owners, s)
+			}
+		}
+	}
+	return []*OwnerClaim{claim}, nil
+}
@@ -0,0 +1,192 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const mockManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: admin
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: admin
+  labels:
+    app: admin
+spec:
+  template:
+    metadata:
+      labels:
+        app: admin
+        owner: admin-team
+`
+
+// makeMockOncall is a stand-in for an on-call rota, knowing only who is on call for admin.
+func makeMockOncall(team string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/admin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"service": "admin", "team": %q}`, team)
+	}))
+}
+
+func TestConsensusCheck_Run(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	consensusTests := []struct {
+		Name     string
+		Owner    string
+		Files    map[string]string
+		Oncall   string
+		Status   Status
+		Findings int
+	}{
+		{"AllAgree", "group:default/admin-team", map[string]string{
+			"/maroda/admin/main/.github/CODEOWNERS": "* @maroda/admin-team\n",
+			"/maroda/admin/main/kube/app.yaml":      mockManifest,
+		}, "Admin-Team", StatusPass, 0},
+		{"OncallDisagrees", "admin-team", map[string]string{
+			"/maroda/admin/main/.github/CODEOWNERS": "* @maroda/admin-team\n",
+			"/maroda/admin/main/kube/app.yaml":      mockManifest,
+		}, "core-team", StatusFail, 1},
+		{"NoManifest", "admin-team", map[string]string{
+			"/maroda/admin/main/.github/CODEOWNERS": "* @maroda/admin-team\n",
+		}, "admin-team", StatusFail, 2},
+		{"EveryoneElseDisagrees", "core-team", map[string]string{
+			"/maroda/admin/main/.github/CODEOWNERS": "* @maroda/admin-team\n",
+			"/maroda/admin/main/kube/app.yaml":      mockManifest,
+		}, "admin-team", StatusFail, 3},
+	}
+
+	for _, tt := range consensusTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gh := makeMockGitHub("main", tt.Files)
+			defer gh.Close()
+			oncall := makeMockOncall(tt.Oncall)
+			defer oncall.Close()
+
+			check, err := newConsensusCheck(map[string]string{
+				"sources":  "catalog, codeowners, kube, oncall",
+				"api":      gh.URL,
+				"manifest": "kube/app.yaml",
+				"oncall":   oncall.URL + "/services/{service}",
+			})
+			assertNoError(t, err)
+
+			r := NewRegistry()
+			r.Register(check)
+			got := r.Run(context.Background(), &SvcConfig{Service: "admin", Owner: tt.Owner})[0]
+
+			assertString(t, string(got.Status), string(tt.Status))
+			assertIDEquals(t, len(got.Findings), tt.Findings)
+		})
+	}
+
+	t.Run("reads the repo and on-call by the entity name", func(t *testing.T) {
+		gh := makeMockGitHub("main", map[string]string{"/maroda/admin/main/CODEOWNERS": "* @maroda/admin-team\n"})
+		defer gh.Close()
+		oncall := makeMockOncall("admin-team")
+		defer oncall.Close()
+
+		check, err := newConsensusCheck(map[string]string{
+			"sources": "catalog, codeowners, oncall",
+			"api":     gh.URL,
+			"oncall":  oncall.URL + "/services/{service}",
+		})
+		assertNoError(t, err)
+		got := check.Run(context.Background(), &SvcConfig{Service: "component:web/admin", Name: "admin", Owner: "admin-team"})
+
+		assertString(t, got.Reality, "catalog=admin-team codeowners=@maroda/admin-team oncall=admin-team")
+		assertBool(t, got.Works, true)
+	})
+
+	t.Run("names the sources that disagree", func(t *testing.T) {
+		gh := makeMockGitHub("main", map[string]string{"/maroda/admin/main/CODEOWNERS": "* @maroda/core-team\n"})
+		defer gh.Close()
+
+		check, err := newConsensusCheck(map[string]string{"api": gh.URL})
+		assertNoError(t, err)
+		got := check.Run(context.Background(), &SvcConfig{Service: "admin", Owner: "admin-team"})
+
+		assertBool(t, got.Present, true)
+		assertBool(t, got.Works, false)
+		assertString(t, got.Reality, "catalog=admin-team codeowners=@maroda/core-team")
+		assertString(t, got.Findings[0], "codeowners: @maroda/core-team disagrees with catalog: admin-team")
+	})
+
+	t.Run("every repo's CODEOWNERS has to agree", func(t *testing.T) {
+		gh := makeMockGitHub("main", map[string]string{
+			"/maroda/admin/main/CODEOWNERS":     "* @maroda/admin-team\n",
+			"/maroda/admin-web/main/CODEOWNERS": "* @maroda/web-team\n",
+		})
+		defer gh.Close()
+
+		check, err := newConsensusCheck(map[string]string{"api": gh.URL})
+		assertNoError(t, err)
+		got := check.Run(context.Background(), &SvcConfig{
+			Service: "admin",
+			Owner:   "admin-team",
+			Repos:   []RepoRef{{Org: "maroda", Repo: "admin"}, {Org: "maroda", Repo: "admin-web", Branch: "main"}},
+		})
+
+		assertBool(t, got.Present, true)
+		assertBool(t, got.Works, false)
+		assertString(t, got.Reality, "catalog=admin-team codeowners(maroda/admin)=@maroda/admin-team codeowners(maroda/admin-web@main)=@maroda/web-team")
+		assertMultiString(t, got.Findings, []string{"codeowners(maroda/admin-web@main): @maroda/web-team disagrees with catalog: admin-team"})
+	})
+
+	t.Run("a source that can't be read is an error", func(t *testing.T) {
+		gh := makeMockGitHub("", nil)
+		defer gh.Close()
+
+		check, err := newConsensusCheck(map[string]string{"api": gh.URL})
+		assertNoError(t, err)
+
+		r := NewRegistry()
+		r.Register(check)
+		got := r.Run(context.Background(), &SvcConfig{Service: "admin", Owner: "admin-team"})[0]
+		assertString(t, string(got.Status), string(StatusError))
+	})
+}
+
+func TestNewConsensusCheck(t *testing.T) {
+	badArgs := []struct {
+		Name string
+		Args map[string]string
+	}{
+		{"OneSource", map[string]string{"sources": "catalog"}},
+		{"UnknownSource", map[string]string{"sources": "catalog,pagerduty"}},
+		{"Twice", map[string]string{"sources": "catalog,catalog"}},
+		{"KubeWithoutManifest", map[string]string{"sources": "catalog,kube"}},
+		{"OncallWithoutURL", map[string]string{"sources": "catalog,oncall", "oncall": "https://oncall.example.com"}},
+		{"UnknownArg", map[string]string{"team": "admin"}},
+	}
+
+	for _, tt := range badArgs {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := newConsensusCheck(tt.Args)
+			assertHasError(t, err)
+		})
+	}
+}
+
+func TestManifestLabels(t *testing.T) {
+	got, err := manifestLabels(mockManifest, "app")
+	assertNoError(t, err)
+	assertMultiString(t, got, []string{"admin"})
+
+	got, err = manifestLabels(mockManifest, "owner")
+	assertNoError(t, err)
+	assertMultiString(t, got, []string{"admin-team"})
+
+	_, err = manifestLabels("kind: [", "owner")
+	assertHasError(t, err)
+}
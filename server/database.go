@@ -81,7 +81,7 @@ func (f *FSStore) GetTriggerID(name string) int {
 // TriggerID increases LastID by one, providing a run count.
 // If it's a new service, create them and start their tally at 1.
 // The var /service/ is a WMService
-func (f *FSStore) TriggerID(name string, score int) {
+func (f *FSStore) TriggerID(name string, run *RunReturn) {
 	service := f.almanac.Find(name)
 
 	// TriggerID needs to set the Score, it is the "trigger" for things happening.
@@ -90,10 +90,20 @@ func (f *FSStore) TriggerID(name string, score int) {
 	// If not, add them to this almanac with an initial LastID.
 	if service != nil {
 		service.LastID++
-		service.Score = score
+		service.Score = run.Score
+		service.Policy = run.Policy
+		service.Verdict = run.Verdict
+		service.SubScore = run.SubScore
 	} else {
-		// Initialize the service in the almanac
-		f.almanac = append(f.almanac, WMService{name, 1, 100})
+		// Initialize the service in the almanac with the results of its first run
+		f.almanac = append(f.almanac, WMService{
+			Name:     name,
+			LastID:   1,
+			Score:    run.Score,
+			Policy:   run.Policy,
+			Verdict:  run.Verdict,
+			SubScore: run.SubScore,
+		})
 	}
 
 	// This seek call isn't needed here because we're seeking to the beginning
@@ -101,6 +111,31 @@ func (f *FSStore) TriggerID(name string, score int) {
 	f.database.Encode(f.almanac)
 }
 
+// AddWaiver stores a Waiver for a service already in the almanac.
+// A Waiver for an unknown service is refused with ServiceNotFound.
+func (f *FSStore) AddWaiver(name string, w Waiver) error {
+	service := f.almanac.Find(name)
+
+	if service == nil {
+		return ServiceNotFound
+	}
+
+	service.Waivers = append(service.Waivers, w)
+	return f.database.Encode(f.almanac)
+}
+
+// GetWaivers is a lookup for every Waiver of a given name, expired or not.
+// The var /service/ is a WMService
+func (f *FSStore) GetWaivers(name string) []Waiver {
+	service := f.almanac.Find(name)
+
+	if service != nil {
+		return service.Waivers
+	}
+
+	return nil
+}
+
 // GetScore is a lookup for the Score for a given name.
 // The var /service/ is a WMService
 func (f *FSStore) GetScore(name string) int {
@@ -1,6 +1,7 @@
 package verificat
 
 import (
+	"io"
 	"log"
 	"os"
 	"testing"
@@ -49,8 +50,8 @@ func TestFileSystemStore(t *testing.T) {
 
 		// This comes back sorted!
 		want := []WMService{
-			{"Craque", 33, 98},
-			{"Mattic", 10, 99},
+			{Name: "Craque", LastID: 33, Score: 98},
+			{Name: "Mattic", LastID: 10, Score: 99},
 		}
 
 		// Almanac returns Name, ID, Score
@@ -97,7 +98,7 @@ func TestFileSystemStore(t *testing.T) {
 		assertNoError(t, err)
 
 		craqueScore := 99
-		store.TriggerID("Craque", craqueScore)
+		store.TriggerID("Craque", &RunReturn{Score: craqueScore})
 
 		got := store.GetTriggerID("Craque")
 		want := 34
@@ -118,13 +119,84 @@ func TestFileSystemStore(t *testing.T) {
 		assertNoError(t, err)
 
 		craqueScore := 99
-		store.TriggerID("Craque", craqueScore)
+		store.TriggerID("Craque", &RunReturn{Score: craqueScore})
 
 		got := store.GetScore("Craque")
 		want := 99
 		assertIDEquals(t, got, want)
 	})
 
+	t.Run("store Verdict and Policy for existing services", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98, "Verdict": "ready"}]`)
+		defer cleanDatabase()
+
+		store, err := NewFSStore(database)
+		assertNoError(t, err)
+
+		store.TriggerID("Craque", &RunReturn{Score: 99, Policy: "golf", Verdict: VerdictNotReady})
+
+		got := store.GetAlmanac().Find("Craque")
+		assertString(t, got.Verdict, VerdictNotReady)
+		assertString(t, got.Policy, "golf")
+	})
+
+	t.Run("store SubScore for existing services", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+		defer cleanDatabase()
+
+		store, err := NewFSStore(database)
+		assertNoError(t, err)
+
+		store.TriggerID("Craque", &RunReturn{Score: 99, SubScore: map[Principle]int{PrincipleDocs: 99}})
+
+		// Read it back from disk to be sure it was written
+		database.Seek(0, io.SeekStart)
+		reloaded, err := NewAlmanac(database)
+		assertNoError(t, err)
+
+		got := Almanac(reloaded).Find("Craque").SubScore[PrincipleDocs]
+		assertIDEquals(t, got, 99)
+	})
+
+	t.Run("store waivers for existing services", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+		defer cleanDatabase()
+
+		store, err := NewFSStore(database)
+		assertNoError(t, err)
+
+		err = store.AddWaiver("Craque", Waiver{Check: "owner", Reason: "reorg", Approver: "sre"})
+		assertNoError(t, err)
+
+		// Read it back from disk to be sure it was written
+		database.Seek(0, io.SeekStart)
+		reloaded, err := NewAlmanac(database)
+		assertNoError(t, err)
+
+		got := Almanac(reloaded).Find("Craque").Waivers
+		assertIDEquals(t, len(got), 1)
+		assertString(t, got[0].Check, "owner")
+	})
+
+	t.Run("refuse waivers for unknown services", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+		defer cleanDatabase()
+
+		store, err := NewFSStore(database)
+		assertNoError(t, err)
+
+		err = store.AddWaiver("Pepper", Waiver{Check: "owner"})
+		assertError(t, err, ServiceNotFound)
+		if store.GetWaivers("Pepper") != nil {
+			t.Errorf("Unknown service has waivers")
+		}
+	})
+
 	t.Run("store LastID for new services", func(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
@@ -136,11 +208,15 @@ func TestFileSystemStore(t *testing.T) {
 			log.Fatalf("New services: problem creating file system service store, %v ", err)
 		}
 
-		store.TriggerID("Pepper", 0)
+		store.TriggerID("Pepper", &RunReturn{Score: 97, Verdict: VerdictReady})
 
 		got := store.GetTriggerID("Pepper")
 		want := 1
 		assertIDEquals(t, got, want)
+
+		// The first run is recorded, not a placeholder
+		assertIDEquals(t, store.GetScore("Pepper"), 97)
+		assertString(t, store.GetAlmanac().Find("Pepper").Verdict, VerdictReady)
 	})
 
 	t.Run("works with an empty file", func(t *testing.T) {
@@ -165,8 +241,8 @@ func TestFileSystemStore(t *testing.T) {
 
 		got := store.GetAlmanac()
 		want := Almanac{
-			{"Craque", 33, 4},
-			{"Mattic", 10, 5},
+			{Name: "Craque", LastID: 33, Score: 4},
+			{Name: "Mattic", LastID: 10, Score: 5},
 		}
 
 		assertAlmanac(t, got, want)
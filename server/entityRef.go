@@ -0,0 +1,88 @@
+package verificat
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// entityKinds are the catalog kinds Verificat can verify.
+var entityKinds = []string{"system", "component", "api", "resource"}
+
+// EntityRef addresses one entity in the service catalog, written as kind:namespace/name
+// The kind and namespace are optional, e.g.: admin, component:admin-web, or api:payments/ledger
+// A missing part is left empty for the catalog to fill in, see WithDefaults.
+type EntityRef struct {
+	Kind      string // One of entityKinds, always lowercase
+	Namespace string
+	Name      string
+}
+
+// ParseEntityRef reads kind:namespace/name, where the kind and namespace are optional.
+func ParseEntityRef(s string) (EntityRef, error) {
+	var ref EntityRef
+
+	rest := strings.TrimSpace(s)
+	if kind, name, ok := strings.Cut(rest, ":"); ok {
+		ref.Kind = strings.ToLower(kind)
+		rest = name
+		if !slices.Contains(entityKinds, ref.Kind) {
+			return ref, fmt.Errorf("entity %q has unsupported kind %q, supported kinds are %v", s, kind, entityKinds)
+		}
+	}
+	if ns, name, ok := strings.Cut(rest, "/"); ok {
+		ref.Namespace = ns
+		rest = name
+		if ns == "" {
+			return ref, fmt.Errorf("entity %q has an empty namespace before /", s)
+		}
+	}
+
+	if rest == "" || strings.ContainsAny(rest, ":/") {
+		return ref, fmt.Errorf("entity %q must be name, kind:name, or kind:namespace/name", s)
+	}
+	ref.Name = rest
+	return ref, nil
+}
+
+// WithDefaults fills in a missing kind and namespace.
+func (er EntityRef) WithDefaults(kind, namespace string) EntityRef {
+	if er.Kind == "" {
+		er.Kind = kind
+	}
+	if er.Namespace == "" {
+		er.Namespace = namespace
+	}
+	return er
+}
+
+// String is the full kind:namespace/name, leaving out any part that's missing.
+func (er EntityRef) String() string {
+	s := er.Name
+	if er.Namespace != "" {
+		s = er.Namespace + "/" + s
+	}
+	if er.Kind != "" {
+		s = er.Kind + ":" + s
+	}
+	return s
+}
+
+// keyName is the bare name in an almanac key, or the key itself when it isn't a ref.
+func keyName(key string) string {
+	if ref, err := ParseEntityRef(key); err == nil {
+		return ref.Name
+	}
+	return key
+}
+
+// Key is how the entity is known in the almanac.
+// A System in the catalog's own namespace is known by its name alone,
+// as every Service was before other kinds could be verified.
+// Anything else is known by its full ref, e.g.: component:default/admin-web
+func (er EntityRef) Key(namespace string) string {
+	if er.Kind == "system" && er.Namespace == namespace {
+		return er.Name
+	}
+	return er.String()
+}
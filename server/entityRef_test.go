@@ -0,0 +1,55 @@
+package verificat
+
+import (
+	"testing"
+)
+
+func TestParseEntityRef(t *testing.T) {
+	refTests := []struct {
+		Ref    string
+		Expect EntityRef
+	}{
+		{"admin", EntityRef{Name: "admin"}},
+		{"component:admin-web", EntityRef{Kind: "component", Name: "admin-web"}},
+		{"payments/ledger", EntityRef{Namespace: "payments", Name: "ledger"}},
+		{"API:payments/ledger-api", EntityRef{Kind: "api", Namespace: "payments", Name: "ledger-api"}},
+		{"resource:default/admin-db", EntityRef{Kind: "resource", Namespace: "default", Name: "admin-db"}},
+	}
+
+	for _, tt := range refTests {
+		t.Run(tt.Ref, func(t *testing.T) {
+			got, err := ParseEntityRef(tt.Ref)
+			assertNoError(t, err)
+			if got != tt.Expect {
+				t.Errorf("got %+v want %+v", got, tt.Expect)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "group:sre", "component:", "/admin", "system:default/", "a/b/c"} {
+		t.Run("refuses "+bad, func(t *testing.T) {
+			_, err := ParseEntityRef(bad)
+			assertHasError(t, err)
+		})
+	}
+}
+
+func TestEntityRef_Key(t *testing.T) {
+	keyTests := []struct {
+		Ref    string
+		Expect string
+	}{
+		{"admin", "admin"},
+		{"system:default/admin", "admin"},
+		{"system:payments/ledger", "system:payments/ledger"},
+		{"component:admin-web", "component:default/admin-web"},
+	}
+
+	for _, tt := range keyTests {
+		t.Run(tt.Ref, func(t *testing.T) {
+			ref, err := ParseEntityRef(tt.Ref)
+			assertNoError(t, err)
+			assertString(t, ref.WithDefaults("system", "default").Key("default"), tt.Expect)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package verificat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Inventory is a local service catalog, a YAML or JSON file listing every Service.
+// It's for teams whose Services aren't in Backstage, e.g.:
+//
+//	services:
+//	  admin:
+//	    owner: code-owners-admin
+//	    labels: {tier: "1"}
+//	    repos: [maroda/admin-api]
+type Inventory struct {
+	Services map[string]InventoryService `yaml:"services"`
+}
+
+// InventoryService is what the Inventory knows about a single Service.
+type InventoryService struct {
+	Kind   string            `yaml:"kind"`   // system or component, defaults to system
+	Owner  string            `yaml:"owner"`  // Should equal CODEOWNERS for its repo in GitHub
+	System string            `yaml:"system"` // The System a Component is part of
+	Labels map[string]string `yaml:"labels"` // Used by a sweep Selector
+	Repos  []string          `yaml:"repos"`  // The repos it lives in, e.g.: maroda/admin-api@main
+}
+
+// FileCat reads Services from an Inventory file.
+// The file is read every time, so edits are picked up without a restart.
+type FileCat struct {
+	Path string     // The Inventory file
+	Svc  *SvcConfig // The Service to read, filled in by ReadSvc
+}
+
+// newFileCatalog reads Services from the Inventory at args.path.
+func newFileCatalog(args map[string]string, _ map[string][]RepoRef, _ Forges) (catalogBackend, error) {
+	if err := checkArgs(args, "path"); err != nil {
+		return nil, err
+	}
+	if args["path"] == "" {
+		return nil, errors.New("path is required")
+	}
+
+	return func(sc *SvcConfig) (Catalog, error) {
+		return &FileCat{Path: args["path"], Svc: sc}, nil
+	}, nil
+}
+
+// ReadSvc finds the Service in the Inventory by name, or by kind:name.
+// The Inventory has no namespaces, so a Service is always known by its name.
+// A Service that isn't listed returns SystemNotRecognized.
+func (fc *FileCat) ReadSvc(ctx context.Context) (string, error) {
+	fc.Svc.Datetime = time.Now().Unix()
+
+	ref, err := ParseEntityRef(fc.Svc.Service)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", SystemNotRecognized, err)
+	}
+
+	entries, err := fc.entries()
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := entries[ref.Name]
+	if !ok || ref.Kind != "" && ref.Kind != entry.Kind {
+		return "", fmt.Errorf("%w: %s in %s", SystemNotRecognized, fc.Svc.Service, fc.Path)
+	}
+
+	fc.Svc.Service = entry.Service
+	fc.Svc.Name = entry.Name
+	fc.Svc.Owner = entry.Owner
+	fc.Svc.Repos = entry.Repos
+	if entry.Kind == "system" {
+		fc.Svc.Members = systemMembers(entry.Name, maps.Values(entries))
+	}
+	slog.Debug("Owner Set", slog.String("Owner", fc.Svc.Owner), slog.Any("Repos", fc.Svc.Repos))
+	return fc.Svc.Owner, nil
+}
+
+// ListSvc lists every Service in the Inventory of the given kinds, system when none are given.
+func (fc *FileCat) ListSvc(ctx context.Context, kinds ...string) ([]CatalogEntry, error) {
+	if len(kinds) == 0 {
+		kinds = []string{"system"}
+	}
+
+	entries, err := fc.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []CatalogEntry
+	for _, entry := range entries {
+		if slices.Contains(kinds, entry.Kind) {
+			listed = append(listed, entry)
+		}
+	}
+
+	sortEntries(listed)
+	return listed, nil
+}
+
+// entries reads and validates the Inventory file.
+func (fc *FileCat) entries() (map[string]CatalogEntry, error) {
+	data, err := os.ReadFile(fc.Path)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading inventory file %s, %v", fc.Path, err)
+	}
+
+	entries, err := ParseInventory(data)
+	if err != nil {
+		return nil, fmt.Errorf("problem loading inventory file %s, %w", fc.Path, err)
+	}
+	return entries, nil
+}
+
+// ParseInventory decodes a YAML or JSON Inventory into a CatalogEntry for each Service.
+// Unknown fields are refused, the same as a Checklist.
+func ParseInventory(data []byte) (map[string]CatalogEntry, error) {
+	inv := new(Inventory)
+	if err := yaml.UnmarshalStrict(data, inv); err != nil {
+		return nil, fmt.Errorf("problem parsing inventory, %v", err)
+	}
+
+	var errs []error
+	entries := make(map[string]CatalogEntry, len(inv.Services))
+	for name, svc := range inv.Services {
+		repos, err := parseRepoRefs(svc.Repos)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("services: %s: %v", name, err))
+			continue
+		}
+
+		kind := strings.ToLower(svc.Kind)
+		if kind == "" {
+			kind = "system"
+		}
+		if !slices.Contains(entityKinds, kind) {
+			errs = append(errs, fmt.Errorf("services: %s: unsupported kind %q, supported kinds are %v", name, svc.Kind, entityKinds))
+			continue
+		}
+
+		entries[name] = CatalogEntry{
+			Service: name,
+			Name:    name,
+			Kind:    kind,
+			Owner:   svc.Owner,
+			System:  svc.System,
+			Labels:  svc.Labels,
+			Repos:   repos,
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid inventory, %w", errors.Join(errs...))
+	}
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+package verificat
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const mockInventory = `
+services:
+  admin:
+    owner: code-owners-admin
+    labels: {tier: "1"}
+    repos: [maroda/admin-api]
+  core:
+    owner: code-owners-core
+  admin-web:
+    kind: Component
+    owner: code-owners-admin
+    system: admin
+`
+
+// writeInventory writes an Inventory file for a test, returning its path.
+func writeInventory(t testing.TB, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("could not write inventory, %v", err)
+	}
+	return path
+}
+
+func TestFileCat_ReadSvc(t *testing.T) {
+	path := writeInventory(t, mockInventory)
+
+	t.Run("reads the owner and repos of a service", func(t *testing.T) {
+		sc := &SvcConfig{Service: "admin"}
+		got, err := ReadinessRead(context.Background(), &FileCat{Path: path, Svc: sc})
+		assertNoError(t, err)
+		assertString(t, got, "code-owners-admin")
+		assertString(t, sc.Repos[0].String(), "maroda/admin-api")
+		if sc.Datetime == 0 {
+			t.Errorf("expected Datetime to be set")
+		}
+	})
+
+	t.Run("lists the Components of a System as members", func(t *testing.T) {
+		sc := &SvcConfig{Service: "admin"}
+		_, err := ReadinessRead(context.Background(), &FileCat{Path: path, Svc: sc})
+		assertNoError(t, err)
+		assertIDEquals(t, len(sc.Members), 1)
+		assertString(t, sc.Members[0].Service, "admin-web")
+	})
+
+	t.Run("reads a service by kind and name", func(t *testing.T) {
+		sc := &SvcConfig{Service: "component:admin-web"}
+		_, err := ReadinessRead(context.Background(), &FileCat{Path: path, Svc: sc})
+		assertNoError(t, err)
+		assertString(t, sc.Service, "admin-web")
+
+		_, err = ReadinessRead(context.Background(), &FileCat{Path: path, Svc: &SvcConfig{Service: "api:admin-web"}})
+		assertError(t, err, SystemNotRecognized)
+	})
+
+	t.Run("handles only services it knows about", func(t *testing.T) {
+		_, err := ReadinessRead(context.Background(), &FileCat{Path: path, Svc: &SvcConfig{Service: "ledger"}})
+		assertError(t, err, SystemNotRecognized)
+	})
+
+	t.Run("returns an error for a missing file", func(t *testing.T) {
+		_, err := ReadinessRead(context.Background(), &FileCat{Path: "testdata/nothing-here.yaml", Svc: &SvcConfig{Service: "admin"}})
+		assertHasError(t, err)
+	})
+}
+
+func TestFileCat_ListSvc(t *testing.T) {
+	fc := &FileCat{Path: writeInventory(t, mockInventory)}
+
+	names := func(entries []CatalogEntry) []string {
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name)
+		}
+		return got
+	}
+
+	t.Run("lists Systems by default", func(t *testing.T) {
+		got, err := fc.ListSvc(context.Background())
+		assertNoError(t, err)
+		if diff := cmp.Diff(names(got), []string{"admin", "core"}); diff != "" {
+			t.Error(diff)
+		}
+		assertString(t, got[0].Labels["tier"], "1")
+	})
+
+	t.Run("lists Components when asked", func(t *testing.T) {
+		got, err := fc.ListSvc(context.Background(), "system", "component")
+		assertNoError(t, err)
+		if diff := cmp.Diff(names(got), []string{"admin", "admin-web", "core"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestParseInventory(t *testing.T) {
+	t.Run("parses a JSON inventory", func(t *testing.T) {
+		got, err := ParseInventory([]byte(`{"services": {"admin": {"owner": "code-owners-admin"}}}`))
+		assertNoError(t, err)
+		assertString(t, got["admin"].Kind, "system")
+	})
+
+	validationTests := []struct {
+		Name   string
+		Data   string
+		Expect string
+	}{
+		{"UnknownField", `services: {admin: {ownr: code-owners-admin}}`, "field ownr not found"},
+		{"BadRepo", `services: {admin: {repos: [admin]}}`, `services: admin: repo "admin" must be org/repo`},
+		{"BadKind", `services: {sre: {kind: group}}`, `services: sre: unsupported kind "group"`},
+	}
+
+	for _, tt := range validationTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			_, err := ParseInventory([]byte(tt.Data))
+			assertGotError(t, err)
+			if err != nil && !strings.Contains(err.Error(), tt.Expect) {
+				t.Errorf("error %q does not contain %q", err, tt.Expect)
+			}
+		})
+	}
+}
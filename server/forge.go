@@ -0,0 +1,289 @@
+package verificat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Forge is where a repo is hosted, and where its CODEOWNERS, manifests, and catalog descriptors are read.
+// GitHub is the default, a repo on another forge is named with its prefix, e.g.: gitlab:platform/infra/admin
+type Forge interface {
+	// DefaultBranch asks for a repo's default branch, e.g.: main or master
+	DefaultBranch(ctx context.Context, ref RepoRef) (string, error)
+
+	// Contents fetches a file from a repo on a branch,
+	// returning its content and the URL it was found at.
+	Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error)
+
+	// RepoFile fetches the first of paths found in a repo, on its branch or else the default branch.
+	RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error)
+
+	// CodeownersLocations are where the forge looks for CODEOWNERS, in the order it looks.
+	CodeownersLocations() []string
+
+	// ParseCodeowners reads the forge's own CODEOWNERS syntax.
+	ParseCodeowners(data string) *Codeowners
+}
+
+// commitReader is a Forge that reads files from a commit rather than a branch, e.g. LocalClient,
+// so the commit can be resolved once and named in the results.
+type commitReader interface {
+	// Commit resolves a repo's branch, or else its default branch, to the commit it points to.
+	Commit(ctx context.Context, ref RepoRef) (string, error)
+}
+
+// pinCommit resolves the commit a repo is read from, once, when its forge reads commits.
+// The ref returned has the commit as its Branch, so every file is read from that commit
+// and one that's missing is reported against it. Any other forge returns ref as it was, and no commit.
+func pinCommit(ctx context.Context, f Forge, ref RepoRef) (RepoRef, string, error) {
+	cr, ok := f.(commitReader)
+	if !ok {
+		return ref, "", nil
+	}
+
+	commit, err := cr.Commit(ctx, ref)
+	if err != nil {
+		return ref, "", err
+	}
+	ref.Branch = commit
+	return ref, commit, nil
+}
+
+// ForgeConfig declares a forge in a Checklist, for the repos named with its prefix.
+// Each of forgeTypes can be used by its type as the prefix without declaring it,
+// which reads the public forge, e.g.: gitlab.com
+// Declaring a forge named github reads every repo without a prefix from it, e.g.: a local mirror.
+type ForgeConfig struct {
+	Type   string `yaml:"type"`   // One of forgeTypes, defaults to the name of the forge
+	API    string `yaml:"api"`    // Optional, e.g.: https://gitlab.example.com/api/v4
+	Token  string `yaml:"token"`  // Optional, the environment variable holding the token, not the token itself
+	Path   string `yaml:"path"`   // For the local type, the directory holding the repos
+	Mirror string `yaml:"mirror"` // For the local type, the forge the repos are copied from, defaults to github
+}
+
+// forgeType is one kind of Forge, with the defaults for its public instance.
+type forgeType struct {
+	API      string                     // The public API
+	Token    string                     // The environment variable holding a token, when none is configured
+	Declared bool                       // There's no public instance, it can only be used once it's declared
+	Nested   bool                       // Repos can be nested in groups, e.g.: platform/infra/admin
+	newForge func(fc ForgeConfig) Forge // Builds the client for a ForgeConfig with its defaults filled in
+}
+
+// forgeTypes are the forges a Checklist can read repos from.
+// GitHub reads GH_TOKEN or a GitHub App, see ghAuth.go, the others read a token if it's set,
+// without one only public repos can be read. A local tree of repos is read with git, see LocalClient.
+var forgeTypes = map[string]forgeType{
+	"github":    {API: ghAPI, newForge: func(fc ForgeConfig) Forge { return gitHubFor(fc.API) }},
+	"gitlab":    {API: glAPI, Token: glTokenEnv, Nested: true, newForge: newGitLabClient},
+	"bitbucket": {API: bbAPI, Token: bbTokenEnv, newForge: newBitbucketClient},
+	"gitea":     {API: gtAPI, Token: gtTokenEnv, newForge: newGiteaClient},
+	"local":     {Declared: true, newForge: newLocalClient},
+}
+
+// Forges are the forges a Checklist declares, by the name its repos use as their prefix.
+// A nil Forges only knows the forges in forgeTypes, by their type.
+type Forges map[string]ForgeConfig
+
+// newForges validates the forges in a Checklist, and fills in their defaults.
+// All problems are reported together, each naming the forge at fault.
+func newForges(conf map[string]ForgeConfig) (Forges, error) {
+	var errs []error
+	forges := make(Forges, len(conf))
+
+	for name, fc := range conf {
+		if name == "" || strings.ContainsAny(name, ":/@") {
+			errs = append(errs, fmt.Errorf("%q is not a valid forge name", name))
+			continue
+		}
+		if fc.Type == "" {
+			fc.Type = name
+		}
+		ft, ok := forgeTypes[fc.Type]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown type %q, known types are %v", name, fc.Type, slices.Sorted(maps.Keys(forgeTypes))))
+			continue
+		}
+		if fc.Type == "github" && fc.Token != "" {
+			errs = append(errs, fmt.Errorf("%s: GitHub is read with %s or a GitHub App, token can't be set", name, ghTokenEnv))
+			continue
+		}
+		if fc.Type == "local" {
+			if fc.Mirror == "" {
+				fc.Mirror = "github"
+			}
+			if err := checkLocal(fc); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", name, err))
+				continue
+			}
+			forges[name] = fc
+			continue
+		}
+		if fc.Path != "" || fc.Mirror != "" {
+			errs = append(errs, fmt.Errorf("%s: path and mirror are only for the local type", name))
+			continue
+		}
+
+		if fc.API == "" {
+			fc.API = ft.API
+		}
+		if fc.Token == "" {
+			fc.Token = ft.Token
+		}
+		fc.API = strings.TrimSuffix(fc.API, "/")
+		forges[name] = fc
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return forges, nil
+}
+
+// known is true for a forge a repo can name: one declared, one of forgeTypes with a public instance,
+// or none at all for GitHub.
+func (fs Forges) known(name string) bool {
+	if _, ok := fs[name]; ok {
+		return true
+	}
+	ft, ok := forgeTypes[name]
+	return name == "" || (ok && !ft.Declared)
+}
+
+// nests is true for a forge whose repos can be nested in groups,
+// or a local mirror of one. GitHub, Gitea, and Bitbucket repos are always org/repo.
+func (fs Forges) nests(name string) bool {
+	if name == "" {
+		name = "github"
+	}
+	fc, ok := fs[name]
+	if !ok {
+		fc = ForgeConfig{Type: name}
+	}
+	if fc.Type == "local" {
+		return forgeTypes[fc.Mirror].Nested
+	}
+	return forgeTypes[fc.Type].Nested
+}
+
+// For is the Forge a repo is on, shared with everything else reading the same forge.
+// A repo without a forge prefix is on the forge declared as github, if there is one.
+// Otherwise it, or a repo with the github prefix, is on the GitHub the Check is configured to read, githubAPI.
+// A repo nested in groups on a forge that can't nest them, e.g. from the catalog, is refused.
+func (fs Forges) For(ref RepoRef, githubAPI string) (Forge, error) {
+	name := ref.Forge
+	if name == "" {
+		name = "github"
+	}
+	if strings.Contains(ref.Org, "/") && !fs.nests(name) {
+		return nil, fmt.Errorf("repo %s can't be nested in groups on forge %q, it must be org/repo", ref, name)
+	}
+
+	fc, ok := fs[name]
+	if !ok {
+		if name == "github" {
+			return gitHubFor(githubAPI), nil
+		}
+		ft, ok := forgeTypes[name]
+		if !ok || ft.Declared {
+			return nil, fmt.Errorf("unknown forge %q for %s, known forges are %v", name, ref, fs.names())
+		}
+		fc = ForgeConfig{Type: name, API: ft.API, Token: ft.Token}
+	}
+	return forgeFor(fc), nil
+}
+
+// names is a sorted list of every forge a repo can name, for error messages.
+func (fs Forges) names() []string {
+	var names []string
+	for name := range fs {
+		names = append(names, name)
+	}
+	for name, ft := range forgeTypes {
+		if _, ok := fs[name]; !ok && !ft.Declared {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// forgeClients are shared by ForgeConfig, a github forge is shared through gitHubFor.
+var (
+	forgeClients   = make(map[ForgeConfig]Forge)
+	forgeClientsMu sync.Mutex
+)
+
+// forgeFor is the shared Forge for a ForgeConfig with its defaults filled in.
+func forgeFor(fc ForgeConfig) Forge {
+	forgeClientsMu.Lock()
+	defer forgeClientsMu.Unlock()
+
+	f, ok := forgeClients[fc]
+	if !ok {
+		f = forgeTypes[fc.Type].newForge(fc)
+		forgeClients[fc] = f
+	}
+	return f
+}
+
+// repoFile is RepoFile for any Forge: the branch of the repo, or else its default branch,
+// is searched for each of paths in turn, and the first one found is returned with its location.
+func repoFile(ctx context.Context, f Forge, ref RepoRef, paths []string) (string, string, error) {
+	branch := ref.Branch
+	if branch == "" {
+		var err error
+		branch, err = f.DefaultBranch(ctx, ref)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	for _, p := range paths {
+		body, location, err := f.Contents(ctx, ref, branch, p)
+		if errors.Is(err, SourceNotFound) {
+			continue
+		}
+		return body, location, err
+	}
+
+	repo := ref
+	repo.Branch = ""
+	return "", "", fmt.Errorf("%w: no %s on %s in %s", SourceNotFound, strings.Join(paths, " or "), branch, repo)
+}
+
+// getRepo reads a forge's JSON description of a repo into repo, with the headers made for each attempt.
+// Forges answer 404 for a private repo the token can't see, so a missing repo
+// is reported as an error with the lookup rather than a finding about the service.
+func (fh *forgeHTTP) getRepo(ctx context.Context, repoURL string, header func(context.Context) (http.Header, error), repo any) error {
+	body, err := fh.getWith(ctx, repoURL, "application/json", header)
+	if errors.Is(err, SourceNotFound) {
+		return fmt.Errorf("repo not found or not accessible at %s", repoURL)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(body), repo); err != nil {
+		return fmt.Errorf("could not read repo from %s, %v", repoURL, err)
+	}
+	return nil
+}
+
+// escapePath escapes each part of a path in a repo, keeping the slashes between them.
+func escapePath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,182 @@
+package verificat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// makeMockForges serves a repo in a nested group on GitLab, and one each on Bitbucket and Gitea,
+// under their own API paths. Files are only served with the forge's own credentials.
+func makeMockForges(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		answers := map[string]struct {
+			Header, Value, Body string
+		}{
+			"/gitlab/api/v4/projects/platform%2Finfra%2Fadmin": {"", "", `{"default_branch":"trunk"}`},
+			"/gitlab/api/v4/projects/platform%2Finfra%2Fadmin/repository/files/.gitlab%2FCODEOWNERS/raw?ref=trunk": {
+				"Private-Token", "gl-token", "[Backend] @platform/infra/admin-team\n* \n",
+			},
+			"/bitbucket/2.0/repositories/maroda/admin": {"", "", `{"mainbranch":{"name":"master"}}`},
+			"/bitbucket/2.0/repositories/maroda/admin/src/master/.bitbucket/CODEOWNERS": {
+				"Authorization", "Bearer bb-token", "* @maroda/admin-team\n",
+			},
+			"/gitea/api/v1/repos/maroda/admin": {"", "", `{"default_branch":"main"}`},
+			"/gitea/api/v1/repos/maroda/admin/raw/docs/CODEOWNERS?ref=main": {
+				"Authorization", "token gt-token", ".* @maroda/admin-team\n",
+			},
+		}
+
+		answer, ok := answers[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if answer.Header != "" && r.Header.Get(answer.Header) != answer.Value {
+			t.Errorf("Expected %s: %s for %s, got %q", answer.Header, answer.Value, r.URL, r.Header.Get(answer.Header))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(answer.Body))
+	}))
+}
+
+// Each forge finds CODEOWNERS where it looks for it, and reads it in its own syntax
+func TestForges_For(t *testing.T) {
+	t.Setenv(glTokenEnv, "gl-token")
+	t.Setenv(bbTokenEnv, "bb-token")
+	t.Setenv("MOCK_GITEA_TOKEN", "gt-token")
+
+	mock := makeMockForges(t)
+	defer mock.Close()
+
+	forges, err := newForges(map[string]ForgeConfig{
+		"gitlab":    {API: mock.URL + "/gitlab/api/v4/"},
+		"bitbucket": {API: mock.URL + "/bitbucket/2.0"},
+		"codeberg":  {Type: "gitea", API: mock.URL + "/gitea/api/v1", Token: "MOCK_GITEA_TOKEN"},
+	})
+	assertNoError(t, err)
+
+	forgeTests := []struct {
+		Repo     string
+		Location string
+		Owners   []string
+	}{
+		{"gitlab:platform/infra/admin", "/gitlab/api/v4/projects/platform%2Finfra%2Fadmin/repository/files/.gitlab%2FCODEOWNERS/raw?ref=trunk", []string{"@platform/infra/admin-team"}},
+		{"bitbucket:maroda/admin", "/bitbucket/2.0/repositories/maroda/admin/src/master/.bitbucket/CODEOWNERS", []string{"@maroda/admin-team"}},
+		{"codeberg:maroda/admin", "/gitea/api/v1/repos/maroda/admin/raw/docs/CODEOWNERS?ref=main", []string{"@maroda/admin-team"}},
+	}
+
+	for _, tt := range forgeTests {
+		t.Run(tt.Repo, func(t *testing.T) {
+			ref, err := ParseRepoRef(tt.Repo)
+			assertNoError(t, err)
+
+			forge, err := forges.For(ref, ghAPI)
+			assertNoError(t, err)
+
+			body, location, err := forge.RepoFile(context.Background(), ref, forge.CodeownersLocations()...)
+			assertNoError(t, err)
+			assertString(t, location, mock.URL+tt.Location)
+			assertMultiString(t, forge.ParseCodeowners(body).CatchAllOwners(), tt.Owners)
+		})
+	}
+
+	t.Run("a missing repo is an error, not a finding", func(t *testing.T) {
+		ref := RepoRef{Forge: "bitbucket", Org: "maroda", Repo: "ledger"}
+		forge, err := forges.For(ref, ghAPI)
+		assertNoError(t, err)
+
+		_, _, err = forge.RepoFile(context.Background(), ref, bbCodeownersLocations...)
+		assertHasError(t, err)
+		if errors.Is(err, SourceNotFound) {
+			t.Errorf("Expected a missing repo not to be SourceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("forges are shared, and GitHub is the default", func(t *testing.T) {
+		a, _ := forges.For(RepoRef{Forge: "gitlab", Org: "a", Repo: "b"}, ghAPI)
+		b, _ := forges.For(RepoRef{Forge: "gitlab", Org: "c", Repo: "d"}, ghAPI)
+		if a != b {
+			t.Errorf("Expected one shared client for the same forge")
+		}
+
+		for _, ref := range []RepoRef{{Org: "maroda", Repo: "admin"}, {Forge: "github", Org: "maroda", Repo: "admin"}} {
+			f, err := forges.For(ref, "https://github.example.com/api/v3")
+			assertNoError(t, err)
+			assertString(t, f.(*GitHubClient).API, "https://github.example.com/api/v3")
+		}
+
+		_, err := forges.For(RepoRef{Forge: "sourcehut", Org: "a", Repo: "b"}, ghAPI)
+		assertHasError(t, err)
+	})
+
+	t.Run("only a forge with groups reads a nested repo", func(t *testing.T) {
+		for _, name := range []string{"", "github", "codeberg", "bitbucket"} {
+			_, err := forges.For(RepoRef{Forge: name, Org: "a/b", Repo: "c"}, ghAPI)
+			assertHasError(t, err)
+		}
+	})
+}
+
+// A GitLab repo is checked against the catalog like any other
+func TestOwnerCheck_Forge(t *testing.T) {
+	t.Setenv(glTokenEnv, "gl-token")
+
+	mock := makeMockForges(t)
+	defer mock.Close()
+
+	oc := &OwnerCheck{API: ghAPI, Org: ghPreURI}
+	forges, err := newForges(map[string]ForgeConfig{"gitlab": {API: mock.URL + "/gitlab/api/v4"}})
+	assertNoError(t, err)
+	oc.useForges(forges)
+
+	sc := &SvcConfig{
+		Service: "admin",
+		Owner:   "group:default/admin-team",
+		Repos:   []RepoRef{{Forge: "gitlab", Org: "platform/infra", Repo: "admin"}},
+	}
+	oc.Owns = "main.go"
+
+	result := oc.Run(context.Background(), sc)
+	assertBool(t, result.Present, true)
+	assertBool(t, result.Works, true)
+	assertString(t, result.Reality, "@platform/infra/admin-team")
+	if !strings.Contains(result.Location, "/gitlab/api/v4/projects/") {
+		t.Errorf("Expected the location in GitLab, got %q", result.Location)
+	}
+}
+
+func TestNewForges(t *testing.T) {
+	forgeTests := []struct {
+		Name string
+		Conf map[string]ForgeConfig
+		Err  string
+	}{
+		{"UnknownType", map[string]ForgeConfig{"sourcehut": {}}, `unknown type "sourcehut"`},
+		{"BadName", map[string]ForgeConfig{"git:lab": {Type: "gitlab"}}, `"git:lab" is not a valid forge name`},
+		{"GitHubToken", map[string]ForgeConfig{"ghe": {Type: "github", Token: "GHE_TOKEN"}}, "token can't be set"},
+	}
+
+	for _, tt := range forgeTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := newForges(tt.Conf)
+			if err == nil || !strings.Contains(err.Error(), tt.Err) {
+				t.Errorf("Expected an error with %q, got %v", tt.Err, err)
+			}
+		})
+	}
+
+	t.Run("defaults are filled in from the type", func(t *testing.T) {
+		forges, err := newForges(map[string]ForgeConfig{"work": {Type: "gitlab", API: "https://gitlab.example.com/api/v4/"}})
+		assertNoError(t, err)
+		assertString(t, forges["work"].API, "https://gitlab.example.com/api/v4")
+		assertString(t, forges["work"].Token, glTokenEnv)
+		assertBool(t, forges.known("work"), true)
+		assertBool(t, forges.known("gitea"), true)
+		assertBool(t, forges.known("sourcehut"), false)
+	})
+}
@@ -0,0 +1,215 @@
+package verificat
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GitHub is read as a GitHub App installation when GH_APP_ID is set,
+// otherwise with a personal access token in GH_TOKEN.
+// The App's private key is given as PEM, or as the path to a PEM file.
+const (
+	ghTokenEnv          = "GH_TOKEN"
+	ghAppIDEnv          = "GH_APP_ID"
+	ghAppInstallEnv     = "GH_APP_INSTALLATION_ID"
+	ghAppKeyEnv         = "GH_APP_PRIVATE_KEY"
+	ghAppKeyFileEnv     = "GH_APP_PRIVATE_KEY_FILE"
+	ghAppJWTLifetime    = 9 * time.Minute // GitHub allows at most 10, minus clock drift
+	ghAppTokenRefreshAt = 5 * time.Minute // An installation token is renewed this long before it expires
+)
+
+// GitHubNotConfigured means there are no usable GitHub credentials.
+// This is a problem with how Verificat is deployed, not with any Service.
+var GitHubNotConfigured = errors.New("GitHub credentials are not configured")
+
+// CheckGitHubAuth reports a missing or malformed GitHub credential,
+// so it can stop startup instead of failing every Check later.
+func CheckGitHubAuth() error {
+	if fillEnvVar(ghAppIDEnv) != "ENOENT" {
+		_, err := newAppAuth(ghAPI)
+		return err
+	}
+	if fillEnvVar(ghTokenEnv) == "ENOENT" {
+		return fmt.Errorf("%w: set %s, or %s, %s, and %s", GitHubNotConfigured, ghTokenEnv, ghAppIDEnv, ghAppInstallEnv, ghAppKeyEnv)
+	}
+	return nil
+}
+
+// token is the bearer token for the next request to this client's API.
+// A GitHub App is read from the environment on first use, and its installation token is reused until it's due for renewal.
+func (gc *GitHubClient) token(ctx context.Context) (string, error) {
+	if fillEnvVar(ghAppIDEnv) == "ENOENT" {
+		token := fillEnvVar(ghTokenEnv)
+		if token == "ENOENT" {
+			slog.Error("Environment Variable not set", slog.String("Key", ghTokenEnv))
+			return "", fmt.Errorf("%w: %s is not set", GitHubNotConfigured, ghTokenEnv)
+		}
+		return token, nil
+	}
+
+	gc.appMu.Lock()
+	defer gc.appMu.Unlock()
+	if gc.app == nil {
+		app, err := newAppAuth(gc.API)
+		if err != nil {
+			return "", err
+		}
+		gc.app = app
+	}
+	return gc.app.installationToken(ctx, gc.client)
+}
+
+// appAuth is a GitHub App installation.
+// A JWT signed with the App's private key is exchanged for an installation token,
+// which lasts an hour and is renewed before it expires.
+type appAuth struct {
+	API            string
+	AppID          string
+	InstallationID string
+	key            *rsa.PrivateKey
+
+	token   string
+	expires time.Time
+}
+
+// newAppAuth reads a GitHub App from the environment.
+func newAppAuth(api string) (*appAuth, error) {
+	app := &appAuth{API: api, AppID: fillEnvVar(ghAppIDEnv), InstallationID: fillEnvVar(ghAppInstallEnv)}
+	if _, err := strconv.ParseInt(app.AppID, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %s must be the numeric App ID, got %q", GitHubNotConfigured, ghAppIDEnv, app.AppID)
+	}
+	if _, err := strconv.ParseInt(app.InstallationID, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %s must be the numeric installation ID, got %q", GitHubNotConfigured, ghAppInstallEnv, app.InstallationID)
+	}
+
+	pemData := fillEnvVar(ghAppKeyEnv)
+	if pemData == "ENOENT" {
+		path := fillEnvVar(ghAppKeyFileEnv)
+		if path == "ENOENT" {
+			return nil, fmt.Errorf("%w: %s is set, but neither %s nor %s is", GitHubNotConfigured, ghAppIDEnv, ghAppKeyEnv, ghAppKeyFileEnv)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot read %s, %v", GitHubNotConfigured, ghAppKeyFileEnv, err)
+		}
+		pemData = string(data)
+	}
+
+	key, err := parseAppKey([]byte(pemData))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", GitHubNotConfigured, err)
+	}
+	app.key = key
+	return app, nil
+}
+
+// parseAppKey reads the RSA private key GitHub generates for an App,
+// PKCS#1 as downloaded, or PKCS#8.
+func parseAppKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the App private key is not PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("the App private key cannot be read, %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the App private key must be an RSA key")
+	}
+	return key, nil
+}
+
+// jwt is signed with the App's private key (RS256), and identifies the App for a short while.
+// It's backdated a minute in case GitHub's clock is behind.
+func (app *appAuth) jwt(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(ghAppJWTLifetime).Unix(),
+		"iss": app.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, app.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// installationToken is the current installation token, renewed when it's close to expiring.
+func (app *appAuth) installationToken(ctx context.Context, client *http.Client) (string, error) {
+	now := time.Now()
+	if app.token != "" && now.Add(ghAppTokenRefreshAt).Before(app.expires) {
+		return app.token, nil
+	}
+
+	jwt, err := app.jwt(now)
+	if err != nil {
+		return "", fmt.Errorf("cannot sign the GitHub App JWT, %v", err)
+	}
+
+	target := app.API + "/app/installations/" + app.InstallationID + "/access_tokens"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Accept", ghAcceptJSON)
+	req.Header.Add("Authorization", "Bearer "+jwt)
+	req.Header.Add("X-GitHub-Api-Version", ghAPIVersion)
+
+	r, err := client.Do(req)
+	if err != nil {
+		slog.Error("Could not reach service", slog.String("URL", target), slog.Any("Error", err))
+		return "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, 512))
+		slog.Error("GitHub App Token Refused", slog.String("URL", target), slog.Int("Status", r.StatusCode))
+		return "", fmt.Errorf("GitHub refused an installation token from %s: %d %s", target, r.StatusCode, body)
+	}
+
+	var answer struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		return "", fmt.Errorf("could not read installation token from %s, %v", target, err)
+	}
+	if answer.Token == "" {
+		return "", fmt.Errorf("no installation token from %s", target)
+	}
+
+	app.token, app.expires = answer.Token, answer.ExpiresAt
+	slog.Info("GitHub App Token Renewed", slog.String("App", app.AppID), slog.String("Installation", app.InstallationID), slog.Time("Expires", app.expires))
+	return app.token, nil
+}
@@ -0,0 +1,176 @@
+package verificat
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockAppKey is a fresh App private key, as PEM.
+func mockAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertNoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+// makeMockGitHubApp hands out installation tokens that expire after lifetime,
+// only for a JWT signed by key for App 42, installation 7.
+// Requests for content are only answered with the latest token.
+func makeMockGitHubApp(t *testing.T, key *rsa.PublicKey, lifetime time.Duration) (*httptest.Server, *int) {
+	issued := new(int)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if r.URL.Path == "/app/installations/7/access_tokens" {
+			assertString(t, r.Method, http.MethodPost)
+			if err := verifyJWT(auth, key); err != nil {
+				t.Error(err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			*issued++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, *issued, time.Now().Add(lifetime).Format(time.RFC3339))
+			return
+		}
+
+		if auth != fmt.Sprintf("ghs_%d", *issued) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("* @maroda/admin-team\n"))
+	})), issued
+}
+
+// verifyJWT checks the RS256 signature and the claims GitHub requires.
+func verifyJWT(jwt string, key *rsa.PublicKey) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("not a JWT: %q", jwt)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return err
+	}
+	if claims.Iss != "42" || claims.Exp-claims.Iat > int64((10*time.Minute).Seconds()) || time.Now().Unix() < claims.Iat {
+		return fmt.Errorf("bad claims: %+v", claims)
+	}
+	return nil
+}
+
+func TestGitHubClient_App(t *testing.T) {
+	key, keyPEM := mockAppKey(t)
+	t.Setenv(ghTokenEnv, "")
+	t.Setenv(ghAppIDEnv, "42")
+	t.Setenv(ghAppInstallEnv, "7")
+	t.Setenv(ghAppKeyEnv, keyPEM)
+
+	t.Run("exchanges a signed JWT for an installation token, and reuses it", func(t *testing.T) {
+		gh, issued := makeMockGitHubApp(t, &key.PublicKey, time.Hour)
+		defer gh.Close()
+
+		gc := NewGitHubClient(gh.URL)
+		for range 3 {
+			got, err := gc.Get(context.Background(), gh.URL+"/repos/maroda/admin/contents/CODEOWNERS?ref=main", ghAcceptRaw)
+			assertNoError(t, err)
+			assertString(t, got, "* @maroda/admin-team\n")
+		}
+		assertIDEquals(t, *issued, 1)
+	})
+
+	t.Run("renews the token before it expires", func(t *testing.T) {
+		gh, issued := makeMockGitHubApp(t, &key.PublicKey, ghAppTokenRefreshAt-time.Second)
+		defer gh.Close()
+
+		gc := NewGitHubClient(gh.URL)
+		for range 2 {
+			_, err := gc.Get(context.Background(), gh.URL+"/repos/maroda/admin/contents/CODEOWNERS?ref=main", ghAcceptRaw)
+			assertNoError(t, err)
+		}
+		assertIDEquals(t, *issued, 2)
+	})
+
+	t.Run("reads the private key from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.pem")
+		assertNoError(t, os.WriteFile(path, []byte(keyPEM), 0600))
+		t.Setenv(ghAppKeyEnv, "")
+		t.Setenv(ghAppKeyFileEnv, path)
+
+		assertNoError(t, CheckGitHubAuth())
+	})
+}
+
+// Missing or malformed credentials are a configuration error
+func TestCheckGitHubAuth(t *testing.T) {
+	_, keyPEM := mockAppKey(t)
+
+	authTests := []struct {
+		Name  string
+		Env   map[string]string
+		Valid bool
+	}{
+		{"Token", map[string]string{ghTokenEnv: "ghp_mock"}, true},
+		{"Nothing", map[string]string{}, false},
+		{"App", map[string]string{ghAppIDEnv: "42", ghAppInstallEnv: "7", ghAppKeyEnv: keyPEM}, true},
+		{"AppWithoutInstallation", map[string]string{ghAppIDEnv: "42", ghAppKeyEnv: keyPEM}, false},
+		{"AppWithoutKey", map[string]string{ghAppIDEnv: "42", ghAppInstallEnv: "7"}, false},
+		{"AppWithBadKey", map[string]string{ghAppIDEnv: "42", ghAppInstallEnv: "7", ghAppKeyEnv: "not a key"}, false},
+		{"AppNamedNotNumbered", map[string]string{ghAppIDEnv: "verificat", ghAppInstallEnv: "7", ghAppKeyEnv: keyPEM}, false},
+	}
+
+	for _, tt := range authTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			for _, env := range []string{ghTokenEnv, ghAppIDEnv, ghAppInstallEnv, ghAppKeyEnv, ghAppKeyFileEnv} {
+				t.Setenv(env, tt.Env[env])
+			}
+
+			err := CheckGitHubAuth()
+			if tt.Valid {
+				assertNoError(t, err)
+			} else {
+				assertError(t, err, GitHubNotConfigured)
+			}
+		})
+	}
+
+	t.Run("a request without credentials is refused before it's sent", func(t *testing.T) {
+		t.Setenv(ghTokenEnv, "")
+		t.Setenv(ghAppIDEnv, "")
+
+		_, err := NewGitHubClient(ghAPI).Get(context.Background(), ghAPI+"/repos/maroda/admin", ghAcceptJSON)
+		assertError(t, err, GitHubNotConfigured)
+	})
+}
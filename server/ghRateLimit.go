@@ -0,0 +1,209 @@
+package verificat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A GitHub answer that might succeed if asked again is retried up to ghRetries times,
+// waiting as long as GitHub says to, or backing off exponentially from ghBackoff with jitter.
+// When less than ghQuotaLow of the rate limit is left, requests are spread out over what's left,
+// so a sweep of the whole catalog slows down instead of running out.
+// No single wait is longer than ghWaitMax, however long until the rate limit resets.
+var (
+	ghRetries    = 3
+	ghBackoff    = 500 * time.Millisecond
+	ghBackoffMax = 8 * time.Second
+	ghWaitMax    = time.Minute
+	ghQuotaLow   = 0.1
+)
+
+// ghCoreResource is the rate limit most of the REST API counts against.
+const ghCoreResource = "core"
+
+// RateLimited means a forge's rate limit ran out,
+// and didn't reset in time for the request.
+var RateLimited = errors.New("rate limit exceeded")
+
+// rateLimit is the X-RateLimit-* headers from the last answer for one resource, e.g.: core
+type rateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// rateLimits is what's known about each rate limit resource for one forge API.
+type rateLimits struct {
+	mu     sync.Mutex
+	limits map[string]rateLimit
+}
+
+// ghQuotaObserver is told what's left of a rate limit after every answer, see watchGitHubQuota.
+var ghQuotaObserver atomic.Pointer[func(api, resource string, remaining int)]
+
+// watchGitHubQuota reports what's left of every forge's rate limit, e.g. to a Prometheus gauge.
+// GitHub, GitLab, and Gitea all answer with X-RateLimit-* headers; Bitbucket doesn't.
+func watchGitHubQuota(observe func(api, resource string, remaining int)) {
+	ghQuotaObserver.Store(&observe)
+}
+
+// observe records the rate limit headers from an answer, if there are any.
+func (fh *forgeHTTP) observe(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	resource := h.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = ghCoreResource
+	}
+
+	fh.quota.mu.Lock()
+	if fh.quota.limits == nil {
+		fh.quota.limits = make(map[string]rateLimit)
+	}
+	fh.quota.limits[resource] = rateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+	fh.quota.mu.Unlock()
+
+	if observe := ghQuotaObserver.Load(); observe != nil {
+		(*observe)(fh.API, resource, remaining)
+	}
+}
+
+// pace is how long to wait before the next request, to make the core rate limit last until it resets.
+// Nothing is waited while more than ghQuotaLow of it is left, and never more than ghWaitMax.
+func (fh *forgeHTTP) pace(now time.Time) time.Duration {
+	fh.quota.mu.Lock()
+	rl, ok := fh.quota.limits[ghCoreResource]
+	fh.quota.mu.Unlock()
+
+	untilReset := rl.Reset.Sub(now)
+	switch {
+	case !ok || untilReset <= 0:
+		return 0
+	case rl.Remaining <= 0:
+		return min(untilReset, ghWaitMax)
+	case float64(rl.Remaining) < ghQuotaLow*float64(rl.Limit):
+		return min(untilReset/time.Duration(rl.Remaining), ghWaitMax)
+	}
+	return 0
+}
+
+// send makes a request until the forge gives an answer worth keeping, see retryAfter.
+// newReq is called for each attempt. Whatever is answered last is returned,
+// unless the wait for the next attempt would outlast ctx.
+func (fh *forgeHTTP) send(ctx context.Context, newReq func() (*http.Request, error)) (*http.Response, error) {
+	if err := waitFor(ctx, fh.pace(time.Now()), fmt.Errorf("%w: little quota is left until it resets", RateLimited)); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := fh.client.Do(req)
+		if err != nil {
+			// Cancelled by the caller, or the forge can't be reached
+			slog.Error("Could not reach service", slog.String("URL", req.URL.String()), slog.Any("Error", err))
+			if ctx.Err() != nil || attempt >= ghRetries {
+				return nil, err
+			}
+			if err := waitFor(ctx, backoff(attempt), err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fh.observe(r.Header)
+
+		wait, retry := retryAfter(r, attempt, time.Now())
+		if !retry || attempt >= ghRetries {
+			return r, nil
+		}
+		r.Body.Close()
+
+		slog.Warn("Retrying Request", slog.String("URL", req.URL.String()), slog.Int("Status", r.StatusCode), slog.Int("Attempt", attempt+1), slog.Duration("Wait", wait))
+		reason := fmt.Errorf("non 200 Status from %s: %d", req.URL, r.StatusCode)
+		if rateLimited(r) {
+			reason = fmt.Errorf("%w: %s", RateLimited, req.URL)
+		}
+		if err := waitFor(ctx, wait, reason); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter decides if an answer is worth asking for again, and how long to wait first.
+//
+//   - 429, and 403 with no rate limit left, are rate limits: wait for Retry-After,
+//     or until X-RateLimit-Reset, whichever the forge sent.
+//   - 500, 502, 503, and 504 are transient: wait for Retry-After, or back off.
+//
+// A wait is never longer than ghWaitMax. Anything else is the answer.
+func retryAfter(r *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	limited := rateLimited(r)
+
+	switch {
+	case limited:
+	case r.StatusCode == http.StatusInternalServerError, r.StatusCode == http.StatusBadGateway,
+		r.StatusCode == http.StatusServiceUnavailable, r.StatusCode == http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, ghWaitMax), true
+	}
+	if limited && r.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(r.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return min(max(time.Unix(reset, 0).Sub(now), 0), ghWaitMax), true
+		}
+	}
+	return backoff(attempt), true
+}
+
+// rateLimited is a 429, or a 403 for running out of either the primary or a secondary rate limit.
+func rateLimited(r *http.Response) bool {
+	return r.StatusCode == http.StatusTooManyRequests ||
+		(r.StatusCode == http.StatusForbidden && (r.Header.Get("X-RateLimit-Remaining") == "0" || r.Header.Get("Retry-After") != ""))
+}
+
+// backoff doubles from ghBackoff with each attempt, up to ghBackoffMax,
+// with full jitter so a sweep's requests don't all come back at once.
+func backoff(attempt int) time.Duration {
+	ceiling := min(ghBackoff<<attempt, ghBackoffMax)
+	return rand.N(ceiling) + 1
+}
+
+// waitFor sleeps, unless ctx would end first.
+// A wait that can't finish in time fails straight away with the reason for waiting,
+// rather than when the deadline passes.
+func waitFor(ctx context.Context, d time.Duration, reason error) error {
+	if d <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return fmt.Errorf("%w, and waiting %v would pass the deadline", reason, d.Round(time.Millisecond))
+	}
+
+	slog.Info("Waiting to Retry", slog.Any("Reason", reason), slog.Duration("Wait", d))
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
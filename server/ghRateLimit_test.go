@@ -0,0 +1,174 @@
+package verificat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// makeMockFlakyGitHub answers with each status in turn, then 200 with the rate limit left.
+func makeMockFlakyGitHub(headers http.Header, statuses ...int) (*httptest.Server, *int) {
+	calls := new(int)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		if *calls <= len(statuses) {
+			for k, v := range headers {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statuses[*calls-1])
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Write([]byte("* @maroda/admin-team\n"))
+	})), calls
+}
+
+func TestGitHubClient_Retry(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	retryTests := []struct {
+		Name     string
+		Headers  http.Header
+		Statuses []int
+		Calls    int
+		Err      bool
+	}{
+		{"Transient", nil, []int{http.StatusBadGateway, http.StatusServiceUnavailable}, 3, false},
+		{"TooManyRequests", http.Header{"Retry-After": {"0"}}, []int{http.StatusTooManyRequests}, 2, false},
+		{"SecondaryRateLimit", http.Header{"Retry-After": {"0"}}, []int{http.StatusForbidden}, 2, false},
+		{"Forbidden", nil, []int{http.StatusForbidden}, 1, true},
+		{"GivesUp", nil, []int{500, 500, 500, 500, 500}, ghRetries + 1, true},
+	}
+
+	for _, tt := range retryTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gh, calls := makeMockFlakyGitHub(tt.Headers, tt.Statuses...)
+			defer gh.Close()
+
+			got, err := NewGitHubClient(gh.URL).Get(context.Background(), gh.URL+"/repos/maroda/admin/contents/CODEOWNERS?ref=main", ghAcceptRaw)
+			assertIDEquals(t, *calls, tt.Calls)
+			if tt.Err {
+				assertHasError(t, err)
+				return
+			}
+			assertNoError(t, err)
+			assertString(t, got, "* @maroda/admin-team\n")
+		})
+	}
+
+	t.Run("a rate limit that won't reset in time fails straight away", func(t *testing.T) {
+		reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		gh, calls := makeMockFlakyGitHub(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}, http.StatusForbidden)
+		defer gh.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		start := time.Now()
+		_, err := NewGitHubClient(gh.URL).Get(ctx, gh.URL+"/repos/maroda/admin", ghAcceptJSON)
+		assertError(t, err, RateLimited)
+		assertIDEquals(t, *calls, 1)
+		if time.Since(start) > 500*time.Millisecond {
+			t.Errorf("Expected no waiting, took %v", time.Since(start))
+		}
+	})
+
+	t.Run("each attempt is sent with the token current at the time", func(t *testing.T) {
+		var seen []string
+		gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, r.Header.Get("Authorization"))
+			if len(seen) == 1 {
+				os.Setenv("GH_TOKEN", "renewed-token")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("* @maroda/admin-team\n"))
+		}))
+		defer gh.Close()
+
+		_, err := NewGitHubClient(gh.URL).Get(context.Background(), gh.URL+"/repos/maroda/admin", ghAcceptJSON)
+		assertNoError(t, err)
+		assertMultiString(t, seen, []string{"Bearer mock-token", "Bearer renewed-token"})
+	})
+}
+
+// No wait is longer than ghWaitMax, however long GitHub asks for
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	reset := strconv.FormatInt(now.Add(time.Hour).Unix(), 10)
+
+	waitTests := []struct {
+		Name    string
+		Status  int
+		Headers http.Header
+		Expect  time.Duration
+	}{
+		{"RetryAfter", http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, 30 * time.Second},
+		{"LongRetryAfter", http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}, ghWaitMax},
+		{"UntilReset", http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}, ghWaitMax},
+	}
+
+	for _, tt := range waitTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, retry := retryAfter(&http.Response{StatusCode: tt.Status, Header: tt.Headers}, 0, now)
+			assertBool(t, retry, true)
+			assertIDEquals(t, int(got), int(tt.Expect))
+		})
+	}
+}
+
+// What's left of the rate limit is reported, and spreads requests out when it's low
+func TestGitHubClient_Quota(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	var api, resource string
+	var remaining int
+	watchGitHubQuota(func(a, r string, n int) { api, resource, remaining = a, r, n })
+	defer ghQuotaObserver.Store(nil)
+
+	gh, _ := makeMockFlakyGitHub(nil)
+	defer gh.Close()
+
+	gc := NewGitHubClient(gh.URL)
+	_, err := gc.Get(context.Background(), gh.URL+"/repos/maroda/admin", ghAcceptJSON)
+	assertNoError(t, err)
+	assertString(t, api, gh.URL)
+	assertString(t, resource, ghCoreResource)
+	assertIDEquals(t, remaining, 4321)
+
+	now := time.Now()
+	paceTests := []struct {
+		Name   string
+		Limit  rateLimit
+		Expect time.Duration
+	}{
+		{"Plenty", rateLimit{Limit: 5000, Remaining: 4321, Reset: now.Add(time.Hour)}, 0},
+		{"Low", rateLimit{Limit: 5000, Remaining: 100, Reset: now.Add(100 * time.Second)}, time.Second},
+		{"None", rateLimit{Limit: 5000, Remaining: 0, Reset: now.Add(time.Minute)}, time.Minute},
+		{"NoneForAnHour", rateLimit{Limit: 5000, Remaining: 0, Reset: now.Add(time.Hour)}, ghWaitMax},
+		{"AlreadyReset", rateLimit{Limit: 5000, Remaining: 0, Reset: now.Add(-time.Minute)}, 0},
+	}
+
+	for _, tt := range paceTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gc.quota.limits[ghCoreResource] = tt.Limit
+			assertIDEquals(t, int(gc.pace(now)), int(tt.Expect))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range 10 {
+		got := backoff(attempt)
+		if got <= 0 || got > ghBackoffMax {
+			t.Errorf("Backoff for attempt %d is %v, want between 0 and %v", attempt, got, ghBackoffMax)
+		}
+	}
+}
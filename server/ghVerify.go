@@ -1,230 +1,390 @@
 package verificat
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
-// Currently CODEOWNERS is the only thing we check in GitHub
-// so this path is very specific.
-// ...
-// https://raw.githubusercontent.com/maroda/verificat/main/.github/CODEOWNERS
+// Currently CODEOWNERS is the only thing we check in GitHub.
+// These are the defaults for OwnerCheck, a checklist file can override them.
+// The branch and location of CODEOWNERS are discovered for each repo, e.g.:
+// https://api.github.com/repos/maroda/verificat/contents/.github/CODEOWNERS?ref=main
 const (
-	ghDomain   = "https://raw.githubusercontent.com"
+	ghAPI      = "https://api.github.com"
 	ghPreURI   = "/maroda/"
-	ghGetPATH  = "/main/.github/CODEOWNERS"
 	webTimeout = 10 * time.Second
-	prefixGH   = "* @maroda/"
-	suffixGH   = "\n"
-	junkGH     = "* @"
+
+	ghAcceptRaw  = "application/vnd.github.raw+json"
+	ghAcceptJSON = "application/vnd.github+json"
+	ghAPIVersion = "2022-11-28"
 )
 
+// codeownersLocations are where GitHub looks for CODEOWNERS, in the order it looks.
+// The first one found is used.
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// SourceNotFound means GitHub answered, but the file isn't there.
+// Unlike an outage, that's a real finding about the service.
+var SourceNotFound = errors.New("source file not found")
+
 type SvcTest interface {
-	TestItem(svc string) *TestReturn
+	TestItems(ctx context.Context, svc string) *RunReturn
 }
 
 // SvcTestDB is the results database.
 // It needs to work in conjunction with the query entry in SvcConfig.
-// This is passed through ReadinessDisplay to operate TestReturn.
+// This is passed through ReadinessDisplay to operate RunReturn.
 // Its values are then available in runVerification,
 // which has access to this struct for adding scoring.
 type SvcTestDB struct {
-	Service  string // The service to test, e.g.: admin
-	Datetime int64  // A start timestamp
-	Owner    string // The retrieved Owner from Backstage
-	Score    int    // Score out of 100 available test points
+	Service  string         // The service to test, e.g.: admin
+	Name     string         // The bare entity name, e.g.: admin-web, see SvcConfig
+	Datetime int64          // A start timestamp
+	Owner    string         // The retrieved Owner from Backstage
+	Score    int            // Score out of 100 available test points
+	Checks   *Registry      // The Checks to run for this service
+	Policy   ScoringPolicy  // How results become a Score, GolfPolicy if nil
+	Waivers  []Waiver       // Accepted failures for this service
+	Repos    []RepoRef      // The repos for this service, from the checklist or catalog
+	Members  []MemberResult // The Components of a System, already verified
+	DryRun   bool           // The results won't be recorded
+}
+
+// RunReturn holds the answers for every Check in this run
+type RunReturn struct {
+	Service  string
+	Score    int
+	Policy   string            // The ScoringPolicy used for Score and SubScore
+	Verdict  string            // ready or not-ready, see Verdict()
+	SubScore map[Principle]int // Score for each of the Eight Principles checked
+	DryRun   bool              `json:",omitempty"` // Not recorded in the almanac
+	Members  []MemberResult    `json:",omitempty"` // The Components a System's Score is rolled up from
+	Results  []*CheckResult
+}
+
+// OwnerCheck is the "Owner" test between Backstage and GitHub.
+// Each field can be set with the same name (lowercase) in checklist args,
+// otherwise the defaults above are used.
+type OwnerCheck struct {
+	API  string // The GitHub API, e.g.: https://api.github.com or https://github.example.com/api/v3
+	Org  string // The GitHub org path for Services without Repos, e.g.: /maroda/
+	Path string // Optional, skips discovery with a fixed branch and path, e.g.: /main/.github/CODEOWNERS
+	Owns string // Compare the owners of this repo path, e.g.: src/, instead of the catch-all rule
+
+	Owners *OwnerNormalizer // The Checklist's owner mapping table, set by useOwners
+	Forges Forges           // The Checklist's forges, for repos outside GitHub, set by useForges
+}
+
+// useOwners is given the Checklist's owner mapping table when the Checklist is built.
+func (oc *OwnerCheck) useOwners(on *OwnerNormalizer) {
+	oc.Owners = on
 }
 
-// TestReturn holds the answers for this test
-type TestReturn struct {
-	Present bool
-	Owner   string
-	Reality string
-	Works   bool
-	Score   int
+// useForges is given the Checklist's forges when the Checklist is built.
+func (oc *OwnerCheck) useForges(fs Forges) {
+	oc.Forges = fs
 }
 
-// urlCat is variadic, concatenating any set of strings into a URL.
-// It can be used to embed a dynamic string alongside static parts of a URI.
-// /u/ is a slice of strings used to build completeURL
-func urlCat(u ...string) string {
-	var completeURL string
-	for _, p := range u {
-		completeURL = completeURL + p
+// newOwnerCheck is the checkFactory for the "owner" type.
+func newOwnerCheck(args map[string]string) (Check, error) {
+	if err := checkArgs(args, "domain", "api", "org", "path", "owns"); err != nil {
+		return nil, err
+	}
+
+	oc := &OwnerCheck{API: ghAPI, Org: ghPreURI}
+	deprecatedDomain(args)
+	if v, ok := args["api"]; ok {
+		oc.API = v
 	}
-	slog.Info("New Source Created", slog.String("URL", completeURL))
-	return completeURL
+	if v, ok := args["org"]; ok {
+		oc.Org = v
+	}
+	if v, ok := args["path"]; ok {
+		oc.Path = v
+	}
+	if v, ok := args["owns"]; ok {
+		oc.Owns = v
+	}
+	return oc, nil
+}
+
+func (oc *OwnerCheck) ID() string { return "owner" }
+
+func (oc *OwnerCheck) Description() string {
+	return "Backstage Owner is present and matches the GitHub CODEOWNERS"
 }
 
-// TestItem is returning a test result to ReadinessDisplay
-// Currently this represents the "Owner" test between Backstage and GitHub
-func (s *SvcTestDB) TestItem(svc string) *TestReturn {
+func (oc *OwnerCheck) Principles() []Principle {
+	return []Principle{PrincipleDocs, PrincipleCatastrophe}
+}
+
+// Run compares the Owner in the catalog with CODEOWNERS in every repo of the Service.
+// A Service spanning several repos passes only when each of them names the owner.
+func (oc *OwnerCheck) Run(ctx context.Context, sc *SvcConfig) *CheckResult {
 	// Check the owner field.
 	// Validation: If it's populated, return true.
 	// Verification: If it's populated with the correct string, return true.
 
 	var present, works bool
 
-	// answer, err := Fetch(urlCat(ghDomain, ghPreURI, svc, ghGetPATH))
-
-	// Get the actual value from CODEOWNERS in the matching GitHub repos
-	// This can take a map of URLs, but for now we only have one to give it.
-	target := urlCat(ghDomain, ghPreURI, svc, ghGetPATH)
-	urls := map[int]string{0: target}
-	answer, err := MultiFetch(urls)
+	// Get the actual value from CODEOWNERS in each of the GitHub repos,
+	// wherever it is on the default branch.
+	claims, err := oc.claims(ctx, sc)
 	if err != nil {
+		// Without the source of truth there's nothing to compare,
+		// this is an error with GitHub, not a failure of the service.
 		slog.Error("Cannot Fetch", slog.Any("Error", err))
+		return &CheckResult{
+			Status:  StatusError,
+			Present: sc.Owner != "",
+			Expect:  sc.Owner,
+			Reason:  err.Error(),
+		}
 	}
 
-	// Strip off the CODEOWNERS formatting
-	subreal := strings.TrimPrefix(strings.TrimSuffix(answer[0], suffixGH), prefixGH)
-	reality := strings.TrimPrefix(subreal, junkGH)
+	// The catalog and CODEOWNERS name owners differently,
+	// both are normalized before comparing, and shown as they were found.
+	// With several repos, each owner is shown as repo=owner.
+	multi := len(claims) > 1
+	expect := oc.Owners.Normalize(sc.Owner, claims[0].Org)
+	var raw, owners, locations, findings []string
+	perRepo := make([]RepoOwners, len(claims))
+	for i, claim := range claims {
+		ro := RepoOwners{Repo: claim.Repo, Owners: claim.Owners, Location: claim.Location, Commit: claim.Commit}
+		for _, o := range claim.Owners {
+			ro.Normalized = append(ro.Normalized, oc.Owners.Normalize(o, claim.Org))
+		}
+		ro.Works = slices.Contains(ro.Normalized, expect)
+		perRepo[i] = ro
+
+		if !multi {
+			raw, owners, findings = claim.Owners, ro.Normalized, claim.Findings
+			locations = append(locations, claim.Location)
+			break
+		}
+		for j, o := range claim.Owners {
+			raw = append(raw, claim.Repo.String()+"="+o)
+			owners = append(owners, claim.Repo.String()+"="+ro.Normalized[j])
+		}
+		for _, f := range claim.Findings {
+			findings = append(findings, claim.Repo.String()+": "+f)
+		}
+		if claim.Location != "" {
+			locations = append(locations, claim.Location)
+		}
+		if sc.Owner != "" && !ro.Works {
+			findings = append(findings, fmt.Sprintf("%s: owned by %s, not %s", claim.Repo, strings.Join(claim.Owners, " "), sc.Owner))
+		}
+	}
+	reality := strings.Join(raw, " ")
 
 	// Check the Owner for any WMService in Backstage
-	if s.Owner == "" {
+	if sc.Owner == "" {
 		// Validation has failed, the field is empty
-		present = false
-		s.Score--
-		slog.Warn("Empty Field", slog.String("Owner", s.Owner))
 		// Verification automatically fails
-		s.Score--
-		slog.Info("New Adjustment", slog.Int("Score", s.Score))
+		present = false
+		slog.Warn("Empty Field", slog.String("Owner", sc.Owner))
 	} else {
 		// Validation succeeds!
 		present = true
-		// Now check if it is equal to the retrieved source of truth
-		if s.Owner != reality {
-			// Verification has failed
-			works = false
-			slog.Warn("Unequal Field", slog.String("Owner", s.Owner), slog.String("Reality", reality))
-			s.Score--
-			slog.Info("New Adjustment", slog.Int("Score", s.Score))
-		} else {
+		// Now check if it is one of the owners at the retrieved source of truth,
+		// in every repo of the Service
+		if !slices.ContainsFunc(perRepo, func(ro RepoOwners) bool { return !ro.Works }) {
 			// Verification succeeds!
 			works = true
-			slog.Info("Matching Field", slog.String("Owner", s.Owner), slog.String("Reality", reality), slog.Int("Score", s.Score))
+			slog.Info("Matching Field", slog.String("Owner", sc.Owner), slog.String("Reality", reality), slog.String("Normalized", expect))
+		} else {
+			// Verification has failed
+			works = false
+			slog.Warn("Unequal Field", slog.String("Owner", sc.Owner), slog.String("Reality", reality), slog.String("Normalized", expect), slog.Any("Owners", owners))
 		}
 	}
 
-	// This will be included in the API return value
-	return &TestReturn{
-		Present: present,
-		Owner:   s.Owner,
-		Reality: reality,
-		Works:   works,
-		Score:   s.Score,
+	// Per-repo results are only worth showing for more than one repo,
+	// or to name the commit a repo was read at
+	if !multi && claims[0].Commit == "" {
+		perRepo = nil
 	}
-}
 
-// ReadinessDisplay takes the data and runs queries for processing and presentation.
-// The first arg /i/ is the catalog with its data.
-// The second is which service is being tested.
-// The third is where this output goes.
-func ReadinessDisplay(i SvcTest, service string, w io.Writer) error {
-	// Our first test is just to verify that the Codeowners field is populated in Backstage.
-	returnedTest := i.TestItem(service)
-	returnOut, err := json.Marshal(returnedTest)
-	if err != nil {
-		slog.Error("Failed to marshal struct to JSON", slog.Any("Error", err))
+	return &CheckResult{
+		Present:    present,
+		Expect:     sc.Owner,
+		Reality:    reality,
+		Normalized: &Comparison{Expect: expect, Reality: owners},
+		Works:      works,
+		Location:   strings.Join(locations, " "),
+		Findings:   findings,
+		Repos:      perRepo,
 	}
-
-	// Print the JSON bytestring and return
-	_, err = fmt.Fprintf(w, "%s", string(returnOut))
-	if err != nil {
-		slog.Error("Failed to print JSON to Writer", slog.Any("Error", err))
-	}
-	return err
 }
 
-// MultiFetch is ConfiguredFetch for multiple urls in a []string
-// It will return the "Answer" value for each URL in a []string with matching indexes
-func MultiFetch(urls map[int]string) ([]string, error) {
-	// ErrorGroup for catching multiple URL fetches,
-	egrp := new(errgroup.Group)
-	// using a limit of 3 concurrent fetches.
-	// egrp.SetLimit(3)
-
-	// This is the same as wwwFetch.Answer but for multiple URLs
-	results := make([]string, len(urls))
+// claims reads CODEOWNERS from every repo of the Service in parallel,
+// returned in the same order as the repos.
+// A missing CODEOWNERS is a finding in its claim, any other error stops the rest.
+func (oc *OwnerCheck) claims(ctx context.Context, sc *SvcConfig) ([]*OwnerClaim, error) {
+	egrp, ctx := errgroup.WithContext(ctx)
+	repos := oc.repos(sc)
+	claims := make([]*OwnerClaim, len(repos))
 
-	// Step through the list and fire off a check
-	for i, url := range urls {
+	for i, ref := range repos {
 		egrp.Go(func() error {
-			answer, err := getGitHub(url)
-			results[i] = answer
+			claim, err := oc.claim(ctx, sc, ref)
+			claims[i] = claim
+			if errors.Is(err, SourceNotFound) {
+				return nil
+			}
 			return err
 		})
 	}
 
 	if err := egrp.Wait(); err != nil {
-		return results, err
+		return claims, err
 	}
-
-	return results, nil
+	return claims, nil
 }
 
-// getGitHub should take the url and a pointer to the results
-// then update the pointer and return only an error
-// TODO: Catch x509 TLS errors
-func getGitHub(currURL string) (string, error) {
-	// Grab GH_TOKEN from the environment
-	// if there's no EnvVar, log an error and go no further
-	envVar := "GH_TOKEN"
-	token := fillEnvVar(envVar)
-	if token == "ENOENT" {
-		slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", token))
-		return token, nil
+// claim reads who CODEOWNERS says owns one repo of the Service, in the syntax of the forge it's on:
+// whoever the catch-all rule names, or the rules for a specific path when one is configured.
+// A missing CODEOWNERS is SourceNotFound, with the claim still holding its findings.
+func (oc *OwnerCheck) claim(ctx context.Context, sc *SvcConfig, ref RepoRef) (*OwnerClaim, error) {
+	claim := &OwnerClaim{Source: "codeowners", Repo: ref, Org: ref.Org}
+
+	forge, err := oc.forge(ref)
+	if err != nil {
+		return claim, err
 	}
 
-	// Build the authHeader with the new token
-	authHeader := "Bearer " + token + ""
+	answer, location, commit, err := oc.codeowners(ctx, forge, ref)
+	claim.Commit = commit
+	if err != nil && !errors.Is(err, SourceNotFound) {
+		return claim, err
+	}
 
-	// Create a new HTTP request object
-	// This will be passed to a new HTTP client below.
-	req, err := http.NewRequest(http.MethodGet, currURL, nil)
-	if err != nil {
-		slog.Error("Could not create http client request", slog.String("URL", currURL), slog.Any("Error", err))
-		return "", err
+	co := forge.ParseCodeowners(answer)
+	if oc.Owns != "" {
+		claim.Owners = co.OwnersOf(oc.Owns)
+	} else {
+		claim.Owners = co.CatchAllOwners()
 	}
 
-	// Add Auth headers to the object.
-	req.Header.Add("Accept", "application/vnd.github.raw+json")
-	req.Header.Add("Authorization", authHeader)
+	claim.Location = location
+	if errors.Is(err, SourceNotFound) {
+		claim.Findings = append(claim.Findings, err.Error())
+	}
+	for _, f := range co.Findings {
+		slog.Warn("Malformed CODEOWNERS", slog.String("Service", sc.Service), slog.String("Finding", f.String()))
+		claim.Findings = append(claim.Findings, f.String())
+	}
+	return claim, err
+}
 
-	// Create a new Client pointer with a configured timeout
-	client := &http.Client{Timeout: webTimeout}
+// codeowners fetches CODEOWNERS for a repo, the URL it was found at,
+// and the commit it was read from when the forge reads commits, see pinCommit.
+// With Path set, that is the only place looked, otherwise the default branch
+// is looked up and each of the forge's CodeownersLocations is tried in turn.
+// A RepoRef with a Branch skips the default branch lookup.
+func (oc *OwnerCheck) codeowners(ctx context.Context, forge Forge, ref RepoRef) (string, string, string, error) {
+	var path string
+	if oc.Path != "" {
+		ref.Branch, path, _ = strings.Cut(strings.TrimPrefix(oc.Path, "/"), "/")
+	}
 
-	// Perform the actual Get.
-	r, err := client.Do(req)
+	ref, commit, err := pinCommit(ctx, forge, ref)
 	if err != nil {
-		slog.Error("Could not reach service", slog.String("URL", currURL), slog.Any("Error", err))
+		return "", "", "", err
 	}
-	defer func() {
-		err := r.Body.Close()
-		if err != nil {
-			slog.Error("Request Body failed to Close", slog.String("URL", currURL), slog.Any("Error", err))
-			return
-		}
-	}()
 
-	if r.StatusCode != http.StatusOK {
-		slog.Error("Non-200 Status", slog.String("URL", currURL), slog.Any("Status", r.StatusCode))
-		return "", errors.New("non 200 Status")
+	var body, location string
+	if oc.Path != "" {
+		body, location, err = forge.Contents(ctx, ref, ref.Branch, path)
+	} else {
+		body, location, err = forge.RepoFile(ctx, ref, forge.CodeownersLocations()...)
 	}
+	return body, location, commit, err
+}
+
+// forge is the shared client for the forge a repo is on,
+// the GitHub API the Check reads unless the repo names another forge.
+func (oc *OwnerCheck) forge(ref RepoRef) (Forge, error) {
+	return oc.Forges.For(ref, oc.API)
+}
 
-	body, err := io.ReadAll(r.Body)
+// repo is where the Service is owned: the first of its repos,
+// or the repo with the same name as the entity in Org.
+func (oc *OwnerCheck) repo(sc *SvcConfig) RepoRef {
+	return oc.repos(sc)[0]
+}
+
+// repos is every repo of the Service,
+// or the repo with the same name as the entity in Org.
+func (oc *OwnerCheck) repos(sc *SvcConfig) []RepoRef {
+	if len(sc.Repos) > 0 {
+		return sc.Repos
+	}
+	return []RepoRef{{Org: strings.Trim(oc.Org, "/"), Repo: sc.entityName()}}
+}
+
+// TestItems is returning every Check result to ReadinessDisplay
+// The ScoringPolicy decides the Score, by default each failed Validation
+// and each failed Verification subtracts one.
+// The context is handed to every Check, so a cancelled run stops its upstream requests.
+func (s *SvcTestDB) TestItems(ctx context.Context, svc string) *RunReturn {
+	if s.Policy == nil {
+		s.Policy = &GolfPolicy{}
+	}
+
+	sc := &SvcConfig{Service: svc, Name: s.Name, Datetime: s.Datetime, Owner: s.Owner, Repos: s.Repos}
+	results := s.Checks.Run(ctx, sc)
+
+	// Waived failures are kept in the results, but not scored
+	ApplyWaivers(results, s.Waivers, time.Now())
+	scored := counted(results)
+
+	// This will be included in the API return value
+	run := &RunReturn{
+		Service:  svc,
+		Score:    s.Policy.Score(scored),
+		Policy:   s.Policy.Name(),
+		Verdict:  Verdict(results),
+		SubScore: SubScores(scored, s.Policy),
+		DryRun:   s.DryRun,
+		Results:  results,
+	}
+	if len(s.Members) > 0 {
+		RollUp(run, s.Members)
+	}
+
+	s.Score = run.Score
+	slog.Info("New Adjustment", slog.String("Service", svc), slog.Int("Score", s.Score), slog.String("Verdict", run.Verdict))
+	return run
+}
+
+// ReadinessDisplay takes the data and runs queries for processing and presentation.
+// The first arg /i/ is the catalog with its data.
+// The second is which service is being tested.
+// The third is where this output goes.
+// The RunReturn is handed back so the caller can record it.
+func ReadinessDisplay(ctx context.Context, i SvcTest, service string, w io.Writer) (*RunReturn, error) {
+	// Every registered Check is run, each reporting its own result.
+	returnedTest := i.TestItems(ctx, service)
+	returnOut, err := json.Marshal(returnedTest)
 	if err != nil {
-		slog.Error("Could not read value at", slog.String("URL", currURL), slog.Any("Error", err))
+		slog.Error("Failed to marshal struct to JSON", slog.Any("Error", err))
 	}
 
-	bodyString := string(body)
-	return bodyString, err
+	// Print the JSON bytestring and return
+	_, err = fmt.Fprintf(w, "%s", string(returnOut))
+	if err != nil {
+		slog.Error("Failed to print JSON to Writer", slog.Any("Error", err))
+	}
+	return returnedTest, err
 }
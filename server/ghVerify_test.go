@@ -2,9 +2,11 @@ package verificat
 
 import (
 	"bytes"
-	"log"
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,14 +22,27 @@ type mockSvcTestDB struct {
 }
 
 // These 'false' values are what comes through the mock call
-// func (db *mockSvcTestDB) TestItem(svc string) (bool, string, string, bool) {
-func (db *mockSvcTestDB) TestItem(svc string) *TestReturn {
-	return &TestReturn{
-		Present: true,
-		Owner:   "mock-admin-group",
-		Reality: "mock-developer-group",
-		Works:   false,
-		Score:   100}
+func (db *mockSvcTestDB) TestItems(ctx context.Context, svc string) *RunReturn {
+	return &RunReturn{
+		Service: svc,
+		Score:   100,
+		Policy:  "golf",
+		Verdict: VerdictNotReady,
+		SubScore: map[Principle]int{
+			PrincipleDocs: 99,
+		},
+		Results: []*CheckResult{{
+			ID:         "owner",
+			Status:     StatusFail,
+			Principles: []Principle{PrincipleDocs},
+			Required:   true,
+			Weight:     1,
+			Present:    true,
+			Works:      false,
+			Expect:     "mock-admin-group",
+			Reality:    "mock-developer-group",
+		}},
+	}
 }
 
 // Is Readiness Display correctly writing results?
@@ -37,10 +52,10 @@ func TestReadinessDisplay(t *testing.T) {
 	mockRD := &mockSvcTestDB{Service: service, Datetime: 1724367242, Owner: "code-owners-admin", Score: 0}
 
 	t.Run("Is Readiness Display correctly writing results?", func(t *testing.T) {
-		// ReadinessDisplay calls TestItem, which needs to send us more data
-		err := ReadinessDisplay(mockRD, service, &buffer)
+		// ReadinessDisplay calls TestItems, which needs to send us more data
+		run, err := ReadinessDisplay(context.Background(), mockRD, service, &buffer)
 		got := buffer.String()
-		want := "{\"Present\":true,\"Owner\":\"mock-admin-group\",\"Reality\":\"mock-developer-group\",\"Works\":false,\"Score\":100}"
+		want := "{\"Service\":\"admin\",\"Score\":100,\"Policy\":\"golf\",\"Verdict\":\"not-ready\",\"SubScore\":{\"documentation\":99},\"Results\":[{\"ID\":\"owner\",\"Status\":\"fail\",\"Principles\":[\"documentation\"],\"Required\":true,\"Weight\":1,\"Present\":true,\"Works\":false,\"Expect\":\"mock-admin-group\",\"Reality\":\"mock-developer-group\"}]}"
 
 		// What we're comparing is the buffer string, not the structs.
 		if diff := cmp.Diff(got, want); diff != "" {
@@ -48,103 +63,343 @@ func TestReadinessDisplay(t *testing.T) {
 		}
 
 		assertError(t, err, nil)
+		assertString(t, run.Verdict, VerdictNotReady)
 	})
 }
 
-// This is an integration test to check that a GitHub URL is reachable.
-// getGitHub checks for the token in the GH_TOKEN EnvVar
-func TestGetGitHub(t *testing.T) {
-	t.Run("Integration: GitHub auth is working", func(t *testing.T) {
-		svc := "verificat"
-		var got string
-		want := "* @maroda\n"
-		url := ghDomain + ghPreURI + svc + ghGetPATH
-		got, err := getGitHub(url)
+// Scoring happens after every Check has reported in.
+func TestSvcTestDB_TestItems(t *testing.T) {
+	t.Run("failed validation and verification both subtract", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&mockCheck{id: "empty", result: CheckResult{}})
+		checks.Register(&mockCheck{id: "unequal", result: CheckResult{Present: true}})
+		checks.Register(&mockCheck{id: "match", result: CheckResult{Present: true, Works: true}})
 
-		assertError(t, err, nil)
-		assertString(t, got, want)
+		stests := &SvcTestDB{Owner: "mock-admin-group", Score: 100, Checks: checks}
+		got := stests.TestItems(context.Background(), "admin")
+
+		assertIDEquals(t, got.Score, 97)
+		assertIDEquals(t, stests.Score, 97)
+		assertIDEquals(t, len(got.Results), 3)
+		assertString(t, got.Verdict, VerdictReady)
+		assertIDEquals(t, got.SubScore[PrincipleStability], 97)
+		assertString(t, got.Policy, "golf")
+	})
+
+	t.Run("a failed required check is not-ready regardless of score", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&listedCheck{
+			Check: &mockCheck{id: "unequal", result: CheckResult{Present: true}},
+			item:  ChecklistItem{ID: "unequal", Required: true},
+		})
+
+		stests := &SvcTestDB{Owner: "mock-admin-group", Score: 100, Checks: checks}
+		got := stests.TestItems(context.Background(), "admin")
+
+		assertIDEquals(t, got.Score, 99)
+		assertString(t, got.Verdict, VerdictNotReady)
+		assertBool(t, got.Results[0].Required, true)
+	})
+
+	t.Run("the scoring policy decides the score", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&mockCheck{id: "empty", result: CheckResult{}})
+		checks.Register(&mockCheck{id: "match", result: CheckResult{Present: true, Works: true}})
+
+		stests := &SvcTestDB{Score: 100, Checks: checks, Policy: &PercentagePolicy{}}
+		got := stests.TestItems(context.Background(), "admin")
+
+		assertIDEquals(t, got.Score, 50)
+		assertString(t, got.Policy, "percentage")
+	})
+
+	t.Run("an active waiver keeps a failure from counting", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&listedCheck{
+			Check: &mockCheck{id: "unequal", result: CheckResult{Present: true}},
+			item:  ChecklistItem{ID: "unequal", Required: true},
+		})
+		waivers := []Waiver{{Check: "unequal", Reason: "migration", Approver: "sre", Expires: time.Now().Add(time.Hour)}}
+
+		stests := &SvcTestDB{Score: 100, Checks: checks, Waivers: waivers}
+		got := stests.TestItems(context.Background(), "admin")
+
+		assertIDEquals(t, got.Score, 100)
+		assertString(t, got.Verdict, VerdictReady)
+		assertString(t, string(got.Results[0].Status), string(StatusWaived))
+		assertString(t, got.Results[0].Waiver.Approver, "sre")
+	})
+
+	t.Run("an expired waiver counts again", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&mockCheck{id: "unequal", result: CheckResult{Present: true}})
+		waivers := []Waiver{{Check: "unequal", Reason: "migration", Approver: "sre", Expires: time.Now().Add(-time.Hour)}}
+
+		stests := &SvcTestDB{Score: 100, Checks: checks, Waivers: waivers}
+		got := stests.TestItems(context.Background(), "admin")
+
+		assertIDEquals(t, got.Score, 99)
+		assertString(t, string(got.Results[0].Status), string(StatusFail))
 	})
 
-	// Test if a bad service returns a non-200
-	t.Run("Integration: GitHub service failure", func(t *testing.T) {
-		svc := "craque"
-		url := ghDomain + ghPreURI + svc + ghGetPATH
-		_, err := getGitHub(url)
+	t.Run("errors and skips don't cost anything", func(t *testing.T) {
+		checks := NewRegistry()
+		checks.Register(&listedCheck{
+			Check: &mockCheck{id: "down", result: CheckResult{Status: StatusError, Reason: "GitHub is down"}},
+			item:  ChecklistItem{ID: "down", Required: true},
+		})
+		checks.Register(&listedCheck{
+			Check: &mockCheck{id: "legacy"},
+			item:  ChecklistItem{ID: "legacy", Skip: []string{"admin"}},
+		})
+
+		stests := &SvcTestDB{Score: 100, Checks: checks}
+		got := stests.TestItems(context.Background(), "admin")
 
-		assertGotError(t, err)
+		assertIDEquals(t, got.Score, 100)
+		assertString(t, got.Verdict, VerdictReady)
+		assertString(t, string(got.Results[0].Status), string(StatusError))
+		assertString(t, string(got.Results[1].Status), string(StatusSkipped))
 	})
 }
 
-func TestMultiFetch(t *testing.T) {
-	t.Run("Returns an answer from multiple endpoints", func(t *testing.T) {
-		// We need a set of test server URLs
-		mockWWW := make(map[int]*httptest.Server)
-		urlsWWW := make(map[int]string)
-
-		// Fill mockWWW with httptest.Servers
-		// Fill urlsWWW with those endpoints
-		for i := 0; i <= 2; i++ {
-			mockWWW[i] = makeMockWebServ(0 * time.Millisecond)
-			urlsWWW[i] = mockWWW[i].URL
+// The Owner check tells a missing CODEOWNERS apart from GitHub being unreachable.
+func TestOwnerCheck_Run(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+	sc := &SvcConfig{Service: "admin", Owner: "admin-team"}
+
+	ownerTests := []struct {
+		Name   string
+		Code   int
+		Body   string
+		Expect Status
+	}{
+		{"Match", http.StatusOK, "* @maroda/admin-team\n", StatusPass},
+		{"OneOfMany", http.StatusOK, "# owners\n* @maroda/sre @maroda/admin-team\n", StatusPass},
+		{"LastMatchWins", http.StatusOK, "* @maroda/admin-team\n* @maroda/core-team\n", StatusFail},
+		{"OtherOrg", http.StatusOK, "* @octo/admin-team\n", StatusFail},
+		{"NoCatchAll", http.StatusOK, "/docs/ @maroda/admin-team\n", StatusFail},
+		{"Mismatch", http.StatusOK, "* @maroda/core-team\n", StatusFail},
+		{"NoCodeowners", http.StatusNotFound, "", StatusFail},
+		{"Outage", http.StatusBadGateway, "", StatusError},
+	}
+
+	for _, tt := range ownerTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.Code)
+				w.Write([]byte(tt.Body))
+			}))
+			defer gh.Close()
+
+			r := NewRegistry()
+			r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI, Path: "/main/.github/CODEOWNERS"})
+			got := r.Run(context.Background(), sc)[0]
+
+			assertString(t, string(got.Status), string(tt.Expect))
+		})
+	}
+
+	t.Run("compares a configured path, and reports findings", func(t *testing.T) {
+		gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("* @maroda/core-team\n/src/ @maroda/admin-team\n!/vendor/ @maroda/core-team\n"))
+		}))
+		defer gh.Close()
+
+		r := NewRegistry()
+		r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI, Path: "/main/.github/CODEOWNERS", Owns: "src/main.go"})
+		got := r.Run(context.Background(), sc)[0]
+
+		assertString(t, string(got.Status), string(StatusPass))
+		assertString(t, got.Reality, "@maroda/admin-team")
+		assertString(t, got.Normalized.Reality[0], "admin-team")
+		assertIDEquals(t, len(got.Findings), 1)
+	})
+
+	t.Run("compares owners through the mapping table", func(t *testing.T) {
+		gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("* @maroda/platform alice@example.com\n"))
+		}))
+		defer gh.Close()
+
+		owners, err := newOwnerNormalizer(OwnerConfig{
+			Aliases: map[string][]string{"platform-team": {"@maroda/platform"}},
+			Users:   map[string]string{"alice@example.com": "group:default/web-team"},
+		})
+		assertNoError(t, err)
+
+		mappingTests := []struct {
+			Owner  string
+			Expect Status
+		}{
+			{"group:default/platform-team", StatusPass},
+			{"platform-team", StatusPass},
+			{"group:default/web-team", StatusPass},
+			{"group:default/core-team", StatusFail},
 		}
 
-		// Now we can send the list to MultiFetch
-		want := []string{"ownership", "ownership", "ownership"}
-		got, err := MultiFetch(urlsWWW)
-		assertMultiString(t, got, want)
-		assertError(t, err, nil)
+		for _, tt := range mappingTests {
+			r := NewRegistry()
+			r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI, Path: "/main/.github/CODEOWNERS", Owners: owners})
+			got := r.Run(context.Background(), &SvcConfig{Service: "admin", Owner: tt.Owner})[0]
 
-		// Clean up resources
-		for i := 0; i <= 2; i++ {
-			mockWWW[i].Close()
+			assertString(t, string(got.Status), string(tt.Expect))
+			assertString(t, got.Expect, tt.Owner)
+			assertString(t, got.Reality, "@maroda/platform alice@example.com")
+			if diff := cmp.Diff(got.Normalized.Reality, []string{"platform-team", "web-team"}); diff != "" {
+				t.Error(diff)
+			}
 		}
 	})
 }
 
-func assertMultiString(t *testing.T, got, want []string) {
-	t.Helper()
-	if diff := cmp.Diff(got, want); diff != "" {
-		t.Error(diff)
+// The default branch is looked up, then each CODEOWNERS location in turn.
+func TestOwnerCheck_Discovery(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+	sc := &SvcConfig{Service: "admin", Owner: "admin-team"}
+
+	discoveryTests := []struct {
+		Name     string
+		Branch   string
+		Files    map[string]string
+		Status   Status
+		Location string
+	}{
+		{"GitHubDir", "main", map[string]string{
+			"/maroda/admin/main/.github/CODEOWNERS": "* @maroda/admin-team\n",
+			"/maroda/admin/main/CODEOWNERS":         "* @maroda/core-team\n",
+		}, StatusPass, "/maroda/admin/main/.github/CODEOWNERS"},
+		{"RootOnMaster", "master", map[string]string{
+			"/maroda/admin/master/CODEOWNERS": "* @maroda/admin-team\n",
+		}, StatusPass, "/maroda/admin/master/CODEOWNERS"},
+		{"Docs", "trunk", map[string]string{
+			"/maroda/admin/trunk/docs/CODEOWNERS": "* @maroda/admin-team\n",
+		}, StatusPass, "/maroda/admin/trunk/docs/CODEOWNERS"},
+		{"Missing", "main", map[string]string{}, StatusFail, ""},
+		{"NoRepo", "", nil, StatusError, ""},
 	}
-}
 
-// Build a URL takes an arbitrary set of pieces and combines them into a browsable URL.
-func TestUrlCat(t *testing.T) {
-	WebDomain := "craque.bandcamp.com"
-	URIPre := "/track/"
-	t.Run("Returns a URL from static strings", func(t *testing.T) {
-		URIDyna := "relaxant" // This should be tested as a var that changes, too
-		URIPost := ""
+	for _, tt := range discoveryTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			gh := makeMockGitHub(tt.Branch, tt.Files)
+			defer gh.Close()
 
-		want := "craque.bandcamp.com/track/relaxant"
-		got := urlCat(WebDomain, URIPre, URIDyna, URIPost)
+			r := NewRegistry()
+			r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI})
+			got := r.Run(context.Background(), sc)[0]
 
-		assertString(t, got, want)
+			assertString(t, string(got.Status), string(tt.Status))
+			if tt.Location != "" {
+				assertString(t, got.Location, contentsURL(gh.URL, tt.Location))
+			}
+		})
+	}
+
+	t.Run("uses the service's repo, and its branch without a lookup", func(t *testing.T) {
+		gh := makeMockGitHub("", map[string]string{
+			"/octo/admin-api/develop/CODEOWNERS": "* @octo/admin-team\n",
+		})
+		defer gh.Close()
+
+		repos := []RepoRef{{Org: "octo", Repo: "admin-api", Branch: "develop"}}
+		r := NewRegistry()
+		r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI})
+		got := r.Run(context.Background(), &SvcConfig{Service: "admin", Owner: "admin-team", Repos: repos})[0]
+
+		assertString(t, string(got.Status), string(StatusPass))
+		assertString(t, got.Location, contentsURL(gh.URL, "/octo/admin-api/develop/CODEOWNERS"))
 	})
 
-	t.Run("Returns a URL from dynamic strings inside static strings", func(t *testing.T) {
-		URIPost := "/listen"
-		three := []string{"relaxant", "manifold", "synapse"}
+	t.Run("every repo of the service names the owner", func(t *testing.T) {
+		gh := makeMockGitHub("", map[string]string{
+			"/maroda/admin-api/main/CODEOWNERS":   "* @maroda/admin-team\n",
+			"/maroda/admin-infra/main/CODEOWNERS": "* @maroda/sre @maroda/admin-team\n",
+			"/maroda/admin-chart/main/CODEOWNERS": "* @maroda/core-team\n",
+		})
+		defer gh.Close()
 
-		for _, h := range three {
-			want := "craque.bandcamp.com/track/" + h + "/listen"
-			got := urlCat(WebDomain, URIPre, h, URIPost)
+		repoTests := []struct {
+			Name   string
+			Repos  []string
+			Expect Status
+			Works  []bool
+		}{
+			{"AllAgree", []string{"maroda/admin-api@main", "maroda/admin-infra@main"}, StatusPass, []bool{true, true}},
+			{"OneDisagrees", []string{"maroda/admin-api@main", "maroda/admin-chart@main"}, StatusFail, []bool{true, false}},
+			{"OneMissing", []string{"maroda/admin-api@main", "maroda/admin-docs@main"}, StatusFail, []bool{true, false}},
+		}
+
+		for _, tt := range repoTests {
+			t.Run(tt.Name, func(t *testing.T) {
+				repos, err := parseRepoRefs(tt.Repos)
+				assertNoError(t, err)
 
-			assertString(t, got, want)
+				r := NewRegistry()
+				r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI})
+				got := r.Run(context.Background(), &SvcConfig{Service: "admin", Owner: "admin-team", Repos: repos})[0]
+
+				assertString(t, string(got.Status), string(tt.Expect))
+				assertIDEquals(t, len(got.Repos), len(tt.Repos))
+				for i, ro := range got.Repos {
+					assertString(t, ro.Repo.String(), tt.Repos[i])
+					assertBool(t, ro.Works, tt.Works[i])
+				}
+			})
 		}
+
+		t.Run("names the repo that disagrees", func(t *testing.T) {
+			repos, _ := parseRepoRefs([]string{"maroda/admin-api@main", "maroda/admin-chart@main"})
+			got := (&OwnerCheck{API: gh.URL, Org: ghPreURI}).Run(context.Background(), &SvcConfig{Service: "admin", Owner: "admin-team", Repos: repos})
+
+			assertString(t, got.Reality, "maroda/admin-api@main=@maroda/admin-team maroda/admin-chart@main=@maroda/core-team")
+			assertString(t, got.Findings[0], "maroda/admin-chart@main: owned by @maroda/core-team, not admin-team")
+		})
+	})
+
+	t.Run("missing CODEOWNERS is a finding", func(t *testing.T) {
+		gh := makeMockGitHub("main", map[string]string{})
+		defer gh.Close()
+
+		r := NewRegistry()
+		r.Register(&OwnerCheck{API: gh.URL, Org: ghPreURI})
+		got := r.Run(context.Background(), sc)[0]
+
+		assertIDEquals(t, len(got.Findings), 1)
 	})
 }
 
-// Mock responder for external API calls
-func makeMockWebServ(delay time.Duration) *httptest.Server {
+// makeMockGitHub answers for one repo, maroda/admin, on the API.
+// An empty branch means the repo doesn't exist.
+// Files are given by /org/repo/branch/path, and served by the contents API.
+func makeMockGitHub(branch string, files map[string]string) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		testAnswer := []byte("ownership")
-		time.Sleep(delay)
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "text/plain")
-		_, err := w.Write(testAnswer)
-		if err != nil {
-			log.Fatalf("ERROR: Could not write to output.")
+		if r.URL.Path == "/repos/maroda/admin" && branch != "" {
+			fmt.Fprintf(w, `{"name":"admin","default_branch":%q}`, branch)
+			return
+		}
+
+		// /repos/org/repo/contents/path?ref=branch
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/repos/"), "/", 4)
+		if len(parts) == 4 && parts[2] == "contents" {
+			key := "/" + parts[0] + "/" + parts[1] + "/" + r.URL.Query().Get("ref") + "/" + parts[3]
+			if body, ok := files[key]; ok {
+				w.Write([]byte(body))
+				return
+			}
 		}
+		w.WriteHeader(http.StatusNotFound)
 	}))
 }
+
+// contentsURL is where makeMockGitHub serves a file given as /org/repo/branch/path.
+func contentsURL(base, file string) string {
+	parts := strings.SplitN(strings.TrimPrefix(file, "/"), "/", 4)
+	return base + "/repos/" + parts[0] + "/" + parts[1] + "/contents/" + parts[3] + "?ref=" + parts[2]
+}
+
+func assertMultiString(t *testing.T, got, want []string) {
+	t.Helper()
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
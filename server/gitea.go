@@ -0,0 +1,81 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Gitea.com is read by default, a self-hosted Gitea or Forgejo is declared in the Checklist's forges.
+// A token in GITEA_TOKEN is sent in the Authorization header.
+const (
+	gtAPI      = "https://gitea.com/api/v1"
+	gtTokenEnv = "GITEA_TOKEN"
+)
+
+// gtCodeownersLocations are where Gitea looks for CODEOWNERS, in the order it looks.
+// https://docs.gitea.com/usage/code-owners
+var gtCodeownersLocations = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+
+// GiteaClient reads repos through the Gitea REST API.
+type GiteaClient struct {
+	*forgeHTTP
+	Token string // The environment variable holding the token
+}
+
+// newGiteaClient is the newForge for the "gitea" type.
+func newGiteaClient(fc ForgeConfig) Forge {
+	return &GiteaClient{forgeHTTP: newForgeHTTP(fc.API), Token: fc.Token}
+}
+
+// repository is the API URL of a repo.
+func (gt *GiteaClient) repository(ref RepoRef) string {
+	return gt.API + "/repos/" + url.PathEscape(ref.Org) + "/" + url.PathEscape(ref.Repo)
+}
+
+// header carries the token, without one only public repos can be read.
+func (gt *GiteaClient) header() http.Header {
+	header := http.Header{"Accept": {"application/json"}}
+	if token := fillEnvVar(gt.Token); token != "ENOENT" {
+		header.Set("Authorization", "token "+token)
+	}
+	return header
+}
+
+func (gt *GiteaClient) DefaultBranch(ctx context.Context, ref RepoRef) (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := gt.getRepo(ctx, gt.repository(ref), fixedHeader(gt.header()), &repo); err != nil {
+		return "", err
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("no default branch for repo at %s", gt.repository(ref))
+	}
+
+	slog.Debug("Default Branch Found", slog.String("URL", gt.repository(ref)), slog.String("Branch", repo.DefaultBranch))
+	return repo.DefaultBranch, nil
+}
+
+// Contents fetches a file raw with the raw API.
+func (gt *GiteaClient) Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error) {
+	target := gt.repository(ref) + "/raw/" + escapePath(path) + "?ref=" + url.QueryEscape(branch)
+
+	body, err := gt.get(ctx, target, gt.header())
+	return body, target, err
+}
+
+func (gt *GiteaClient) RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error) {
+	return repoFile(ctx, gt, ref, paths)
+}
+
+func (gt *GiteaClient) CodeownersLocations() []string {
+	return gtCodeownersLocations
+}
+
+// ParseCodeowners reads Gitea's CODEOWNERS syntax, with regular expressions.
+func (gt *GiteaClient) ParseCodeowners(data string) *Codeowners {
+	return parseCodeowners(data, giteaCodeowners)
+}
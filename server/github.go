@@ -0,0 +1,269 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ghCacheSize is how many answers each forge client keeps.
+// Enough for CODEOWNERS, a manifest, and catalog-info.yaml in a few hundred repos.
+const ghCacheSize = 2048
+
+// GitHubClient reads repos through the GitHub REST API.
+// API is https://api.github.com, or https://HOSTNAME/api/v3 for GitHub Enterprise Server.
+//
+// One http.Client is reused for every request, and every answer with an ETag is cached.
+// Asking again sends If-None-Match, and GitHub answers 304 Not Modified,
+// which is served from the cache and doesn't count against the rate limit.
+type GitHubClient struct {
+	*forgeHTTP
+
+	appMu sync.Mutex // Guards app, shared by every request
+	app   *appAuth   // The GitHub App installation, when GH_APP_ID is set
+}
+
+// NewGitHubClient returns a GitHubClient for an API base URL, with its own cache.
+func NewGitHubClient(api string) *GitHubClient {
+	return &GitHubClient{forgeHTTP: newForgeHTTP(api)}
+}
+
+// gitHubClients are shared by API base URL,
+// so every Check and catalog reading the same GitHub shares one cache.
+// Other forges are shared the same way by forgeFor.
+var (
+	gitHubClients   = make(map[string]*GitHubClient)
+	gitHubClientsMu sync.Mutex
+)
+
+// gitHubFor is the shared GitHubClient for an API base URL.
+func gitHubFor(api string) *GitHubClient {
+	gitHubClientsMu.Lock()
+	defer gitHubClientsMu.Unlock()
+
+	api = strings.TrimSuffix(api, "/")
+	gc, ok := gitHubClients[api]
+	if !ok {
+		gc = NewGitHubClient(api)
+		gitHubClients[api] = gc
+	}
+	return gc
+}
+
+// DefaultBranch asks for a repo's default branch, e.g.: main or master
+func (gc *GitHubClient) DefaultBranch(ctx context.Context, ref RepoRef) (string, error) {
+	repoURL := gc.API + "/repos/" + url.PathEscape(ref.Org) + "/" + url.PathEscape(ref.Repo)
+
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := gc.getRepo(ctx, repoURL, gc.header(ghAcceptJSON), &repo); err != nil {
+		return "", err
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("no default branch for repo at %s", repoURL)
+	}
+
+	slog.Debug("Default Branch Found", slog.String("URL", repoURL), slog.String("Branch", repo.DefaultBranch))
+	return repo.DefaultBranch, nil
+}
+
+// Contents fetches a file from a repo on a branch with the contents API,
+// returning its content and the URL it was found at.
+func (gc *GitHubClient) Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error) {
+	target := gc.API + "/repos/" + url.PathEscape(ref.Org) + "/" + url.PathEscape(ref.Repo) +
+		"/contents/" + escapePath(path) + "?ref=" + url.QueryEscape(branch)
+
+	body, err := gc.Get(ctx, target, ghAcceptRaw)
+	return body, target, err
+}
+
+// RepoFile fetches the first of paths found in a repo, on its branch or else the default branch.
+// The location of the file is returned with its content.
+func (gc *GitHubClient) RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error) {
+	return repoFile(ctx, gc, ref, paths)
+}
+
+// CodeownersLocations are where GitHub looks for CODEOWNERS.
+func (gc *GitHubClient) CodeownersLocations() []string {
+	return codeownersLocations
+}
+
+// ParseCodeowners reads GitHub's CODEOWNERS syntax.
+func (gc *GitHubClient) ParseCodeowners(data string) *Codeowners {
+	return ParseCodeowners(data)
+}
+
+// Get requests a URL with a GitHub token, asking for a media type in the Accept header.
+// The request is abandoned when ctx is cancelled or its deadline passes.
+// A 404 is SourceNotFound, anything else but 200 (or 304 for a cached answer) is an error,
+// once a rate limited or failing GitHub has been retried, see send.
+func (gc *GitHubClient) Get(ctx context.Context, currURL, accept string) (string, error) {
+	return gc.getWith(ctx, currURL, accept, gc.header(accept))
+}
+
+// header makes the headers for each attempt at a request, asking for a media type.
+// A personal token from GH_TOKEN, or a GitHub App installation token,
+// without either go no further. It's asked for on every attempt,
+// so an installation token that expires while a retry waits is renewed.
+func (gc *GitHubClient) header(accept string) func(context.Context) (http.Header, error) {
+	return func(ctx context.Context) (http.Header, error) {
+		token, err := gc.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return http.Header{
+			"Accept":               {accept},
+			"Authorization":        {"Bearer " + token},
+			"X-Github-Api-Version": {ghAPIVersion},
+		}, nil
+	}
+}
+
+// forgeHTTP is what every forge's REST client shares:
+// one http.Client, a cache of answers by ETag, and the rate limit left.
+type forgeHTTP struct {
+	API string
+
+	client *http.Client
+	cache  *etagCache
+	quota  rateLimits
+}
+
+// newForgeHTTP is a client for an API base URL, with its own cache.
+func newForgeHTTP(api string) *forgeHTTP {
+	return &forgeHTTP{
+		API:    strings.TrimSuffix(api, "/"),
+		client: &http.Client{Timeout: webTimeout},
+		cache:  newEtagCache(ghCacheSize),
+	}
+}
+
+// get requests a URL with the headers a forge wants, usually its credentials and Accept.
+// The request is abandoned when ctx is cancelled or its deadline passes.
+// A 404 is SourceNotFound, anything else but 200 (or 304 for a cached answer) is an error,
+// once a rate limited or failing forge has been retried, see send.
+func (fh *forgeHTTP) get(ctx context.Context, currURL string, header http.Header) (string, error) {
+	return fh.getWith(ctx, currURL, header.Get("Accept"), fixedHeader(header))
+}
+
+// fixedHeader sends the same headers on every attempt, for credentials that don't expire.
+func fixedHeader(header http.Header) func(context.Context) (http.Header, error) {
+	return func(context.Context) (http.Header, error) {
+		return header.Clone(), nil
+	}
+}
+
+// getWith is get, with the headers made for each attempt,
+// for credentials that can expire while a retry waits.
+// Answers are cached by accept, the media type asked for, and the URL.
+func (fh *forgeHTTP) getWith(ctx context.Context, currURL, accept string, header func(context.Context) (http.Header, error)) (string, error) {
+	// Ask only for what's changed since the cached answer
+	key := accept + " " + currURL
+	cached, isCached := fh.cache.get(key)
+
+	r, err := fh.send(ctx, func() (*http.Request, error) {
+		h, err := header(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, currURL, nil)
+		if err != nil {
+			slog.Error("Could not create http client request", slog.String("URL", currURL), slog.Any("Error", err))
+			return nil, err
+		}
+		req.Header = h
+		if isCached {
+			req.Header.Add("If-None-Match", cached.ETag)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err := r.Body.Close()
+		if err != nil {
+			slog.Error("Request Body failed to Close", slog.String("URL", currURL), slog.Any("Error", err))
+			return
+		}
+	}()
+
+	switch {
+	case r.StatusCode == http.StatusNotModified && isCached:
+		slog.Debug("Not Modified", slog.String("URL", currURL), slog.String("ETag", cached.ETag))
+		return cached.Body, nil
+	case r.StatusCode == http.StatusNotFound:
+		slog.Warn("Source Not Found", slog.String("URL", currURL))
+		return "", fmt.Errorf("%w: %s", SourceNotFound, currURL)
+	case rateLimited(r):
+		slog.Error("Rate Limited", slog.String("URL", currURL), slog.Any("Status", r.StatusCode))
+		return "", fmt.Errorf("%w: %s", RateLimited, currURL)
+	case r.StatusCode != http.StatusOK:
+		slog.Error("Non-200 Status", slog.String("URL", currURL), slog.Any("Status", r.StatusCode))
+		return "", fmt.Errorf("non 200 Status from %s: %d", currURL, r.StatusCode)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Could not read value at", slog.String("URL", currURL), slog.Any("Error", err))
+		return "", err
+	}
+
+	if etag := r.Header.Get("ETag"); etag != "" {
+		fh.cache.put(key, cachedAnswer{ETag: etag, Body: string(body)})
+	}
+	return string(body), nil
+}
+
+// deprecatedDomain warns about the domain arg, which set where raw files were fetched.
+// Files are now read with the contents API, so only the api arg is used.
+func deprecatedDomain(args map[string]string) {
+	if v, ok := args["domain"]; ok {
+		slog.Warn("Deprecated Arg, files are read from the api", slog.String("Arg", "domain"), slog.String("Value", v))
+	}
+}
+
+// cachedAnswer is a forge's answer, and the ETag to ask if it has changed.
+type cachedAnswer struct {
+	ETag string
+	Body string
+}
+
+// etagCache holds up to size answers, dropping the oldest first when full.
+type etagCache struct {
+	mu      sync.Mutex
+	size    int
+	answers map[string]cachedAnswer
+	order   []string
+}
+
+func newEtagCache(size int) *etagCache {
+	return &etagCache{size: size, answers: make(map[string]cachedAnswer)}
+}
+
+func (ec *etagCache) get(key string) (cachedAnswer, bool) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	answer, ok := ec.answers[key]
+	return answer, ok
+}
+
+func (ec *etagCache) put(key string, answer cachedAnswer) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	if _, ok := ec.answers[key]; !ok {
+		if len(ec.order) >= ec.size {
+			delete(ec.answers, ec.order[0])
+			ec.order = ec.order[1:]
+		}
+		ec.order = append(ec.order, key)
+	}
+	ec.answers[key] = answer
+}
@@ -0,0 +1,111 @@
+package verificat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// An Enterprise Server API lives under /api/v3, and files are read with the contents API
+func TestGitHubClient_RepoFile(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	ghe := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/api/v3/repos/maroda/admin":
+			w.Write([]byte(`{"default_branch":"trunk"}`))
+		case "/api/v3/repos/maroda/admin/contents/docs/CODEOWNERS?ref=trunk":
+			assertString(t, r.Header.Get("Accept"), ghAcceptRaw)
+			assertString(t, r.Header.Get("Authorization"), "Bearer mock-token")
+			w.Write([]byte("* @maroda/admin-team\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ghe.Close()
+
+	gc := NewGitHubClient(ghe.URL + "/api/v3/")
+	ref := RepoRef{Org: "maroda", Repo: "admin"}
+
+	body, location, err := gc.RepoFile(context.Background(), ref, codeownersLocations...)
+	assertNoError(t, err)
+	assertString(t, body, "* @maroda/admin-team\n")
+	assertString(t, location, ghe.URL+"/api/v3/repos/maroda/admin/contents/docs/CODEOWNERS?ref=trunk")
+
+	_, _, err = gc.RepoFile(context.Background(), ref, "catalog-info.yaml")
+	assertError(t, err, SourceNotFound)
+}
+
+// A repeated request is conditional, and Not Modified is answered from the cache
+func TestGitHubClient_Get(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	var full, notModified int
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("* @maroda/admin-team\n"))
+	}))
+	defer gh.Close()
+
+	gc := NewGitHubClient(gh.URL)
+	for range 3 {
+		got, err := gc.Get(context.Background(), gh.URL+"/repos/maroda/admin/contents/CODEOWNERS?ref=main", ghAcceptRaw)
+		assertNoError(t, err)
+		assertString(t, got, "* @maroda/admin-team\n")
+	}
+	assertIDEquals(t, full, 1)
+	assertIDEquals(t, notModified, 2)
+
+	t.Run("every Check reading the same GitHub shares a client", func(t *testing.T) {
+		if gitHubFor(gh.URL) != gitHubFor(gh.URL+"/") {
+			t.Errorf("Expected one shared client for %s", gh.URL)
+		}
+	})
+}
+
+func TestEtagCache(t *testing.T) {
+	ec := newEtagCache(2)
+	ec.put("a", cachedAnswer{ETag: "1", Body: "a"})
+	ec.put("b", cachedAnswer{ETag: "1", Body: "b"})
+	ec.put("a", cachedAnswer{ETag: "2", Body: "a2"})
+	ec.put("c", cachedAnswer{ETag: "1", Body: "c"})
+
+	_, ok := ec.get("a")
+	assertBool(t, ok, false)
+	got, ok := ec.get("c")
+	assertBool(t, ok, true)
+	assertString(t, got.Body, "c")
+	assertIDEquals(t, len(ec.answers), 2)
+}
+
+// A cancelled context stops a request in flight
+func TestGitHubClient_Cancel(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewGitHubClient(slow.URL).Get(ctx, slow.URL+"/repos/maroda/admin/contents/CODEOWNERS?ref=main", ghAcceptRaw)
+
+	assertError(t, err, context.DeadlineExceeded)
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("request was not cancelled, took %v", elapsed)
+	}
+}
@@ -0,0 +1,83 @@
+package verificat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLab.com is read by default, a self-managed GitLab is declared in the Checklist's forges.
+// A token in GITLAB_TOKEN is sent as PRIVATE-TOKEN, it needs the read_api scope.
+const (
+	glAPI      = "https://gitlab.com/api/v4"
+	glTokenEnv = "GITLAB_TOKEN"
+)
+
+// glCodeownersLocations are where GitLab looks for CODEOWNERS, in the order it looks.
+// https://docs.gitlab.com/user/project/codeowners/#codeowners-file
+var glCodeownersLocations = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// GitLabClient reads repos (projects) through the GitLab REST API.
+// A project's org is its full group path, including any subgroups, e.g.: gitlab:platform/infra/admin
+type GitLabClient struct {
+	*forgeHTTP
+	Token string // The environment variable holding the token
+}
+
+// newGitLabClient is the newForge for the "gitlab" type.
+func newGitLabClient(fc ForgeConfig) Forge {
+	return &GitLabClient{forgeHTTP: newForgeHTTP(fc.API), Token: fc.Token}
+}
+
+// project is the API URL of a project, by its URL-encoded path.
+func (gl *GitLabClient) project(ref RepoRef) string {
+	return gl.API + "/projects/" + url.PathEscape(ref.Org+"/"+ref.Repo)
+}
+
+// header carries the token, without one only public projects can be read.
+func (gl *GitLabClient) header() http.Header {
+	header := http.Header{"Accept": {"application/json"}}
+	if token := fillEnvVar(gl.Token); token != "ENOENT" {
+		header.Set("Private-Token", token)
+	}
+	return header
+}
+
+func (gl *GitLabClient) DefaultBranch(ctx context.Context, ref RepoRef) (string, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := gl.getRepo(ctx, gl.project(ref), fixedHeader(gl.header()), &project); err != nil {
+		return "", err
+	}
+	if project.DefaultBranch == "" {
+		return "", fmt.Errorf("no default branch for project at %s", gl.project(ref))
+	}
+
+	slog.Debug("Default Branch Found", slog.String("URL", gl.project(ref)), slog.String("Branch", project.DefaultBranch))
+	return project.DefaultBranch, nil
+}
+
+// Contents fetches a file raw with the repository files API.
+func (gl *GitLabClient) Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error) {
+	target := gl.project(ref) + "/repository/files/" + url.PathEscape(strings.Trim(path, "/")) + "/raw?ref=" + url.QueryEscape(branch)
+
+	body, err := gl.get(ctx, target, gl.header())
+	return body, target, err
+}
+
+func (gl *GitLabClient) RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error) {
+	return repoFile(ctx, gl, ref, paths)
+}
+
+func (gl *GitLabClient) CodeownersLocations() []string {
+	return glCodeownersLocations
+}
+
+// ParseCodeowners reads GitLab's CODEOWNERS syntax, with sections.
+func (gl *GitLabClient) ParseCodeowners(data string) *Codeowners {
+	return parseCodeowners(data, gitlabCodeowners)
+}
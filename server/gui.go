@@ -9,9 +9,10 @@ import (
 
 // AlmanacWeb stores only data required for rendering the webpage
 type AlmanacWeb struct {
-	Title     string  // HTML Doc Title
-	Content   string  // SVG XML
-	FullScore Almanac // All I'm doing right now is printing the data, no fancy display yet.
+	Title      string      // HTML Doc Title
+	Content    string      // SVG XML
+	FullScore  Almanac     // All I'm doing right now is printing the data, no fancy display yet.
+	Objectives *SROSummary // Percent of Services meeting their SRO, if any are measured
 }
 
 // Load template directory
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	approvals "github.com/approvals/go-approval-tests"
 )
@@ -12,6 +13,9 @@ func TestMain(m *testing.M) {
 	// Configure local dir for approval golden copies
 	approvals.UseFolder("testdata")
 
+	// Retry GitHub without waiting around
+	ghBackoff = time.Millisecond
+
 	// Run tests
 	exitVal := m.Run()
 
@@ -0,0 +1,197 @@
+package verificat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// mirroredForge is where a forge keeps CODEOWNERS, and the syntax it's written in,
+// for reading a local copy of that forge's repos.
+type mirroredForge struct {
+	Locations []string
+	Syntax    *codeownersSyntax
+}
+
+// mirroredForges are the forges a local tree can be a copy of, see ForgeConfig.Mirror.
+var mirroredForges = map[string]mirroredForge{
+	"github":    {Locations: codeownersLocations, Syntax: githubCodeowners},
+	"gitlab":    {Locations: glCodeownersLocations, Syntax: gitlabCodeowners},
+	"bitbucket": {Locations: bbCodeownersLocations, Syntax: githubCodeowners},
+	"gitea":     {Locations: gtCodeownersLocations, Syntax: giteaCodeowners},
+}
+
+// LocalClient reads repos from a local directory tree instead of a forge's API,
+// so a Service can be verified air-gapped, and against a known commit.
+// Each repo is a git repo at Path/org/repo, or a bare clone at Path/org/repo.git,
+// e.g.: a mirror kept with git clone --mirror, or the checkouts in a CI workspace.
+//
+// Files are read from a commit with git, never from a working tree.
+// The commit is part of every Location, e.g.: /srv/mirror/maroda/admin.git@3f2a…:CODEOWNERS
+// and the Checks resolve it once per repo with Commit, to name it in their results.
+type LocalClient struct {
+	Path   string // The root of the tree, e.g.: /srv/mirror/github.com
+	Mirror string // The forge the repos are copied from, one of mirroredForges
+}
+
+// newLocalClient is the newForge for the "local" type.
+func newLocalClient(fc ForgeConfig) Forge {
+	return &LocalClient{Path: fc.Path, Mirror: fc.Mirror}
+}
+
+// checkLocal validates a local ForgeConfig when the Checklist is built,
+// so a missing tree or git is found at startup instead of by every Check.
+func checkLocal(fc ForgeConfig) error {
+	if fc.API != "" || fc.Token != "" {
+		return errors.New("a local forge is read from its path, api and token can't be set")
+	}
+	if _, ok := mirroredForges[fc.Mirror]; !ok {
+		return fmt.Errorf("unknown mirror %q, known mirrors are %v", fc.Mirror, slices.Sorted(maps.Keys(mirroredForges)))
+	}
+	if fc.Path == "" {
+		return errors.New("path is required, the directory holding the repos")
+	}
+	if info, err := os.Stat(fc.Path); err != nil || !info.IsDir() {
+		return fmt.Errorf("path %s is not a directory", fc.Path)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is needed to read repos from %s, %v", fc.Path, err)
+	}
+	return nil
+}
+
+// repoDir is where a repo is in the tree: a clone at org/repo, or else a bare clone at org/repo.git.
+// A repo that isn't there is an error with the tree, not a finding about the service,
+// the same as a repo a forge can't find.
+func (lc *LocalClient) repoDir(ref RepoRef) (string, error) {
+	base := filepath.Join(lc.Path, filepath.FromSlash(ref.Org), ref.Repo)
+	if !strings.HasPrefix(base, filepath.Clean(lc.Path)+string(filepath.Separator)) {
+		return "", fmt.Errorf("repo %s is outside %s", ref, lc.Path)
+	}
+	for _, dir := range []string{base, base + ".git"} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("repo %s not found at %s or %s.git", ref, base, base)
+}
+
+// git runs a git command in a repo and returns what it printed.
+// The tree may belong to another user, e.g. a volume shared with the mirror job, so it's marked safe.
+func (lc *LocalClient) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "safe.directory=" + dir, "-C", dir}, args...)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s in %s, %v: %s", strings.Join(args, " "), dir, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// DefaultBranch is the branch HEAD points to: the default branch of a mirror,
+// or whatever is checked out in a workspace. A detached HEAD, as CI usually checks out, is read as HEAD.
+func (lc *LocalClient) DefaultBranch(ctx context.Context, ref RepoRef) (string, error) {
+	dir, err := lc.repoDir(ref)
+	if err != nil {
+		return "", err
+	}
+
+	branch, err := lc.git(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		slog.Debug("Detached HEAD", slog.String("Repo", dir))
+		return "HEAD", nil
+	}
+
+	slog.Debug("Default Branch Found", slog.String("Repo", dir), slog.String("Branch", strings.TrimSpace(branch)))
+	return strings.TrimSpace(branch), nil
+}
+
+// commit resolves a branch, tag, or commit to the commit it names.
+// A branch only fetched from origin, as in a workspace, is found as origin/branch.
+func (lc *LocalClient) commit(ctx context.Context, dir, branch string) (string, error) {
+	var errs []error
+	for _, rev := range []string{branch, "origin/" + branch} {
+		sha, err := lc.git(ctx, dir, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(sha), nil
+		}
+		errs = append(errs, err)
+		if branch == "HEAD" {
+			break
+		}
+	}
+	return "", fmt.Errorf("no commit for %s in %s, %w", branch, dir, errors.Join(errs...))
+}
+
+// Commit is the commit a repo's branch points to, or else the commit of its default branch.
+func (lc *LocalClient) Commit(ctx context.Context, ref RepoRef) (string, error) {
+	dir, err := lc.repoDir(ref)
+	if err != nil {
+		return "", err
+	}
+
+	branch := ref.Branch
+	if branch == "" {
+		if branch, err = lc.DefaultBranch(ctx, ref); err != nil {
+			return "", err
+		}
+	}
+
+	sha, err := lc.commit(ctx, dir, branch)
+	if err != nil {
+		return "", err
+	}
+	slog.Debug("Local Commit Resolved", slog.String("Repo", ref.String()), slog.String("Branch", branch), slog.String("Commit", sha))
+	return sha, nil
+}
+
+// Contents reads a file from the commit a branch points to.
+// The location names the repo, the commit, and the path, so a result can be traced to exactly what was read.
+func (lc *LocalClient) Contents(ctx context.Context, ref RepoRef, branch, path string) (string, string, error) {
+	dir, err := lc.repoDir(ref)
+	if err != nil {
+		return "", "", err
+	}
+	sha, err := lc.commit(ctx, dir, branch)
+	if err != nil {
+		return "", "", err
+	}
+
+	path = strings.Trim(path, "/")
+	location := dir + "@" + sha + ":" + path
+	if _, err := lc.git(ctx, dir, "cat-file", "-e", sha+":"+path); err != nil {
+		slog.Warn("Source Not Found", slog.String("Location", location))
+		return "", location, fmt.Errorf("%w: %s", SourceNotFound, location)
+	}
+
+	body, err := lc.git(ctx, dir, "cat-file", "blob", sha+":"+path)
+	if err != nil {
+		return "", location, err
+	}
+
+	slog.Info("Local Commit Read", slog.String("Repo", ref.String()), slog.String("Commit", sha), slog.String("Path", path))
+	return body, location, nil
+}
+
+func (lc *LocalClient) RepoFile(ctx context.Context, ref RepoRef, paths ...string) (string, string, error) {
+	return repoFile(ctx, lc, ref, paths)
+}
+
+// CodeownersLocations are where the forge the repos are copied from looks for CODEOWNERS.
+func (lc *LocalClient) CodeownersLocations() []string {
+	return mirroredForges[lc.Mirror].Locations
+}
+
+// ParseCodeowners reads CODEOWNERS in the syntax of the forge the repos are copied from.
+func (lc *LocalClient) ParseCodeowners(data string) *Codeowners {
+	return parseCodeowners(data, mirroredForges[lc.Mirror].Syntax)
+}
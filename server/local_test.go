@@ -0,0 +1,168 @@
+package verificat
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGit runs git in dir as a test author, returning what it printed.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=verificat", "-c", "user.email=verificat@example.com", "-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v, %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// makeMockMirror builds a tree with a bare mirror at maroda/admin.git, tagged v1 before CODEOWNERS changed,
+// and a CI workspace at maroda/web with HEAD detached, as a CI checkout leaves it.
+// It returns the root of the tree, and the commit on main.
+func makeMockMirror(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	src := t.TempDir()
+	runGit(t, src, "init", "--quiet", "--initial-branch=main")
+	assertNoError(t, os.MkdirAll(filepath.Join(src, ".github"), 0755))
+	assertNoError(t, os.WriteFile(filepath.Join(src, ".github", "CODEOWNERS"), []byte("* @maroda/old-team\n"), 0644))
+	runGit(t, src, "add", ".")
+	runGit(t, src, "commit", "--quiet", "-m", "Add CODEOWNERS")
+	runGit(t, src, "tag", "v1")
+	assertNoError(t, os.WriteFile(filepath.Join(src, ".github", "CODEOWNERS"), []byte("* @maroda/admin-team\n"), 0644))
+	runGit(t, src, "commit", "--quiet", "-am", "Hand over admin")
+	sha := runGit(t, src, "rev-parse", "HEAD")
+
+	root := t.TempDir()
+	assertNoError(t, os.MkdirAll(filepath.Join(root, "maroda"), 0755))
+	runGit(t, root, "clone", "--quiet", "--mirror", src, filepath.Join(root, "maroda", "admin.git"))
+	runGit(t, root, "clone", "--quiet", src, filepath.Join(root, "maroda", "web"))
+	runGit(t, filepath.Join(root, "maroda", "web"), "checkout", "--quiet", "--detach", "v1")
+
+	return root, sha
+}
+
+// Files are read from a commit, and the commit is in the location
+func TestLocalClient_RepoFile(t *testing.T) {
+	root, sha := makeMockMirror(t)
+	lc := &LocalClient{Path: root, Mirror: "github"}
+	ctx := context.Background()
+
+	localTests := []struct {
+		Name   string
+		Ref    RepoRef
+		Expect string
+	}{
+		{"DefaultBranch", RepoRef{Org: "maroda", Repo: "admin"}, "* @maroda/admin-team\n"},
+		{"Tag", RepoRef{Org: "maroda", Repo: "admin", Branch: "v1"}, "* @maroda/old-team\n"},
+		{"DetachedWorkspace", RepoRef{Org: "maroda", Repo: "web"}, "* @maroda/old-team\n"},
+		{"OriginBranchInWorkspace", RepoRef{Org: "maroda", Repo: "web", Branch: "main"}, "* @maroda/admin-team\n"},
+	}
+
+	for _, tt := range localTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			body, _, err := lc.RepoFile(ctx, tt.Ref, lc.CodeownersLocations()...)
+			assertNoError(t, err)
+			assertString(t, body, tt.Expect)
+		})
+	}
+
+	t.Run("the location names the commit", func(t *testing.T) {
+		_, location, err := lc.RepoFile(ctx, RepoRef{Org: "maroda", Repo: "admin"}, lc.CodeownersLocations()...)
+		assertNoError(t, err)
+		assertString(t, location, filepath.Join(root, "maroda", "admin.git")+"@"+sha+":.github/CODEOWNERS")
+	})
+
+	t.Run("a missing file is SourceNotFound", func(t *testing.T) {
+		_, _, err := lc.RepoFile(ctx, RepoRef{Org: "maroda", Repo: "admin"}, catalogInfoFile)
+		assertError(t, err, SourceNotFound)
+	})
+
+	t.Run("a missing repo or branch is an error, not a finding", func(t *testing.T) {
+		for _, ref := range []RepoRef{
+			{Org: "maroda", Repo: "ledger"},
+			{Org: "maroda", Repo: "admin", Branch: "trunk"},
+			{Org: "maroda/..", Repo: "..", Branch: "main"},
+		} {
+			_, _, err := lc.RepoFile(ctx, ref, lc.CodeownersLocations()...)
+			assertHasError(t, err)
+			if errors.Is(err, SourceNotFound) {
+				t.Errorf("Expected %s not to be SourceNotFound, got %v", ref, err)
+			}
+		}
+	})
+}
+
+// A local mirror declared as github is read for every repo, and GitHub isn't needed
+func TestLocalClient_Checklist(t *testing.T) {
+	root, sha := makeMockMirror(t)
+
+	cl, err := ParseChecklist([]byte(`{checks: [{id: owner, type: owner}], forges: {github: {type: local, path: "` + root + `"}}}`))
+	assertNoError(t, err)
+	assertBool(t, cl.ReadsGitHub(), false)
+
+	sc := &SvcConfig{Service: "admin", Owner: "group:default/admin-team"}
+	got := cl.Registry().Run(context.Background(), sc)[0]
+	assertString(t, string(got.Status), string(StatusPass))
+	if !strings.Contains(got.Location, sha) {
+		t.Errorf("Expected the commit %s in the location, got %q", sha, got.Location)
+	}
+	assertIDEquals(t, len(got.Repos), 1)
+	assertString(t, got.Repos[0].Commit, sha)
+
+	t.Run("a missing CODEOWNERS names the commit that was read", func(t *testing.T) {
+		cl, err := ParseChecklist([]byte(`{checks: [{id: owner, type: owner}], forges: {github: {type: local, path: "` + root + `", mirror: gitea}}}`))
+		assertNoError(t, err)
+
+		got := cl.Registry().Run(context.Background(), sc)[0]
+		assertString(t, string(got.Status), string(StatusFail))
+		assertString(t, got.Repos[0].Commit, sha)
+		if len(got.Findings) == 0 || !strings.Contains(got.Findings[0], "on "+sha+" in maroda/admin") {
+			t.Errorf("Expected a finding naming the commit %s, got %q", sha, got.Findings)
+		}
+	})
+
+	t.Run("the kube source names the commit it read", func(t *testing.T) {
+		oc := &OwnerCheck{API: ghAPI, Org: ghPreURI, Forges: cl.forges}
+		claims, err := kubeOwners{OwnerCheck: oc, Manifest: "kube/app.yaml", Label: "owner"}.Claims(context.Background(), sc)
+		assertError(t, err, SourceNotFound)
+		assertString(t, claims[0].Commit, sha)
+	})
+
+	t.Run("a local forge can be chosen per service", func(t *testing.T) {
+		cl, err := ParseChecklist([]byte(`{checks: [{id: owner, type: owner}], forges: {mirror: {type: local, path: "` + root + `"}}, services: {admin: {repos: ["mirror:maroda/admin"]}}}`))
+		assertNoError(t, err)
+		assertBool(t, cl.ReadsGitHub(), true)
+		assertString(t, cl.Repos()["admin"][0].Forge, "mirror")
+	})
+
+	localTests := []struct {
+		Name   string
+		Data   string
+		Expect string
+	}{
+		{"NoPath", `{checks: [{id: a, type: owner}], forges: {mirror: {type: local}}}`, "forges: mirror: path is required"},
+		{"NotADirectory", `{checks: [{id: a, type: owner}], forges: {mirror: {type: local, path: /no/such/mirror}}}`, "is not a directory"},
+		{"UnknownMirror", `{checks: [{id: a, type: owner}], forges: {mirror: {type: local, path: /, mirror: svn}}}`, `unknown mirror "svn"`},
+		{"WithAPI", `{checks: [{id: a, type: owner}], forges: {mirror: {type: local, path: /, api: "https://gitlab.com/api/v4"}}}`, "api and token can't be set"},
+		{"PathOnRemote", `{checks: [{id: a, type: owner}], forges: {gitlab: {path: /srv/mirror}}}`, "only for the local type"},
+		{"Undeclared", `{checks: [{id: a, type: owner}], services: {admin: {repos: ["local:maroda/admin"]}}}`, `unknown forge "local"`},
+	}
+
+	for _, tt := range localTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			_, err := ParseChecklist([]byte(tt.Data))
+			if err == nil || !strings.Contains(err.Error(), tt.Expect) {
+				t.Errorf("Expected an error with %q, got %v", tt.Expect, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package verificat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Objective is a Service Readiness Objective (SRO), the bar a Service must meet.
+type Objective struct {
+	MinScore int  `yaml:"minScore"` // The lowest passing Score
+	Ready    bool `yaml:"ready"`    // The Verdict must also be ready
+}
+
+// Objectives holds an Objective for each service tier, e.g.: tier-1.
+// A Service is assigned a tier in the Checklist, or gets DefaultTier.
+type Objectives struct {
+	DefaultTier string               `yaml:"defaultTier"`
+	Tiers       map[string]Objective `yaml:"tiers"`
+
+	tierOf map[string]string // Service name to tier, from Checklist.Services
+}
+
+// SROStatus is how a Service measures up to the Objective for its tier.
+// Margin is how far the Score is above (or below, when negative) MinScore.
+type SROStatus struct {
+	Tier     string
+	MinScore int
+	Ready    bool
+	Met      bool
+	Margin   int
+}
+
+// SROSummary is the single number leadership wants:
+// what percent of Services with an Objective are meeting it.
+// A Service that has an Objective but has never been verified has no Verdict,
+// it's Unmeasured rather than counted as missing its Objective.
+type SROSummary struct {
+	Services   int     // Every Service in the almanac
+	Measured   int     // Verified Services that have an Objective for their tier
+	Unmeasured int     // Services with an Objective that have never been verified
+	Met        int     // Services meeting their Objective
+	Percent    float64 // Met out of Measured
+}
+
+// validate checks every tier is usable, given the tier for each Service.
+func (o *Objectives) validate(tierOf map[string]string) error {
+	var errs []error
+
+	for tier, obj := range o.Tiers {
+		if obj.MinScore < 0 || obj.MinScore > 100 {
+			errs = append(errs, fmt.Errorf("tier %q minScore must be between 0 and 100, got %d", tier, obj.MinScore))
+		}
+	}
+
+	if _, ok := o.Tiers[o.DefaultTier]; o.DefaultTier != "" && !ok {
+		errs = append(errs, fmt.Errorf("defaultTier %q has no objective in tiers", o.DefaultTier))
+	}
+
+	for name, tier := range tierOf {
+		if _, ok := o.Tiers[tier]; tier != "" && !ok {
+			errs = append(errs, fmt.Errorf("service %q tier %q has no objective in tiers", name, tier))
+		}
+	}
+
+	o.tierOf = tierOf
+	return errors.Join(errs...)
+}
+
+// Tier is the tier a Service has been assigned, or the DefaultTier.
+// Tiers are assigned by bare name, so name may also be the Service's almanac key.
+func (o *Objectives) Tier(name string) string {
+	if tier := o.tierOf[keyName(name)]; tier != "" {
+		return tier
+	}
+	return o.DefaultTier
+}
+
+// Evaluate measures a Service against the Objective for its tier.
+// A Service without an Objective returns nil.
+func (o *Objectives) Evaluate(ws *WMService) *SROStatus {
+	tier := o.Tier(ws.Name)
+	obj, ok := o.Tiers[tier]
+	if !ok {
+		return nil
+	}
+
+	margin := ws.Score - obj.MinScore
+	met := margin >= 0
+	if obj.Ready && ws.Verdict != VerdictReady {
+		met = false
+	}
+
+	return &SROStatus{
+		Tier:     tier,
+		MinScore: obj.MinScore,
+		Ready:    obj.Ready,
+		Met:      met,
+		Margin:   margin,
+	}
+}
+
+// EvaluateAlmanac returns a copy of the Almanac with each SRO filled in.
+// The SRO is never stored, it's measured against the current Objectives on every read.
+func (o *Objectives) EvaluateAlmanac(a Almanac) Almanac {
+	evaluated := make(Almanac, len(a))
+	for i, ws := range a {
+		ws.SRO = o.Evaluate(&ws)
+		evaluated[i] = ws
+	}
+	return evaluated
+}
+
+// Summary counts how many Services in the Almanac are meeting their Objective.
+func (o *Objectives) Summary(a Almanac) *SROSummary {
+	summary := &SROSummary{Services: len(a)}
+	for _, ws := range a {
+		sro := o.Evaluate(&ws)
+		if sro == nil {
+			continue
+		}
+		if ws.Verdict == "" {
+			summary.Unmeasured++
+			continue
+		}
+		summary.Measured++
+		if sro.Met {
+			summary.Met++
+		}
+	}
+
+	if summary.Measured > 0 {
+		summary.Percent = 100 * float64(summary.Met) / float64(summary.Measured)
+	}
+	return summary
+}
@@ -0,0 +1,100 @@
+package verificat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testObjectives has a strict tier-1 and a lenient default tier-3
+func testObjectives(t testing.TB) *Objectives {
+	t.Helper()
+	o := &Objectives{
+		DefaultTier: "tier-3",
+		Tiers: map[string]Objective{
+			"tier-1": {MinScore: 97, Ready: true},
+			"tier-3": {MinScore: 90},
+		},
+	}
+	err := o.validate(map[string]string{"admin": "tier-1", "core": "tier-1"})
+	assertNoError(t, err)
+	return o
+}
+
+func TestObjectives_Evaluate(t *testing.T) {
+	o := testObjectives(t)
+
+	evaluateTests := []struct {
+		Name    string
+		Service WMService
+		Expect  *SROStatus
+	}{
+		{"MeetsTier", WMService{Name: "admin", Score: 98, Verdict: VerdictReady},
+			&SROStatus{Tier: "tier-1", MinScore: 97, Ready: true, Met: true, Margin: 1}},
+		{"BelowTier", WMService{Name: "admin", Score: 95, Verdict: VerdictReady},
+			&SROStatus{Tier: "tier-1", MinScore: 97, Ready: true, Met: false, Margin: -2}},
+		{"NotReady", WMService{Name: "core", Score: 100, Verdict: VerdictNotReady},
+			&SROStatus{Tier: "tier-1", MinScore: 97, Ready: true, Met: false, Margin: 3}},
+		{"DefaultTier", WMService{Name: "ads", Score: 90, Verdict: VerdictNotReady},
+			&SROStatus{Tier: "tier-3", MinScore: 90, Ready: false, Met: true, Margin: 0}},
+	}
+
+	for _, tt := range evaluateTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := o.Evaluate(&tt.Service)
+			if diff := cmp.Diff(got, tt.Expect); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("no objective without a tier", func(t *testing.T) {
+		none := &Objectives{}
+		if got := none.Evaluate(&WMService{Name: "admin", Score: 100}); got != nil {
+			t.Errorf("expected no SRO, got %+v", got)
+		}
+	})
+}
+
+func TestObjectives_Summary(t *testing.T) {
+	o := testObjectives(t)
+	almanac := Almanac{
+		{Name: "admin", Score: 98, Verdict: VerdictReady},
+		{Name: "core", Score: 96, Verdict: VerdictReady},
+		{Name: "ads", Score: 91, Verdict: VerdictNotReady},
+		{Name: "web", Score: 80, Verdict: VerdictNotReady},
+		{Name: "mail", Score: 0},
+	}
+
+	got := o.Summary(almanac)
+	want := &SROSummary{Services: 5, Measured: 4, Unmeasured: 1, Met: 2, Percent: 50}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("evaluating does not change the stored almanac", func(t *testing.T) {
+		evaluated := o.EvaluateAlmanac(almanac)
+		assertBool(t, evaluated[0].SRO.Met, true)
+		if almanac[0].SRO != nil {
+			t.Errorf("stored almanac was changed: %+v", almanac[0])
+		}
+	})
+}
+
+func TestObjectives_validate(t *testing.T) {
+	validateTests := []struct {
+		Name   string
+		Obj    Objectives
+		TierOf map[string]string
+	}{
+		{"MinScoreTooHigh", Objectives{Tiers: map[string]Objective{"tier-1": {MinScore: 101}}}, nil},
+		{"UnknownDefault", Objectives{DefaultTier: "tier-9", Tiers: map[string]Objective{"tier-1": {}}}, nil},
+		{"UnknownServiceTier", Objectives{Tiers: map[string]Objective{"tier-1": {}}}, map[string]string{"admin": "tier-2"}},
+	}
+
+	for _, tt := range validateTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			assertGotError(t, tt.Obj.validate(tt.TierOf))
+		})
+	}
+}
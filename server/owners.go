@@ -0,0 +1,143 @@
+package verificat
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// OwnerConfig is the mapping table for owner identities in a Checklist.
+// The catalog and GitHub name the same owner differently,
+// e.g.: group:default/platform-team in Backstage is @maroda/platform-team in CODEOWNERS.
+// Every name in the table can be written in any of those forms.
+type OwnerConfig struct {
+	Orgs    []string            `yaml:"orgs"`    // GitHub orgs whose teams are known by the team name alone, e.g.: maroda
+	Aliases map[string][]string `yaml:"aliases"` // Each team, and the other names it goes by
+	Users   map[string]string   `yaml:"users"`   // A user (@user or email) and the team they own on behalf of
+}
+
+// OwnerNormalizer turns every name for an owner into one identity, so they can be compared.
+// A nil OwnerNormalizer only understands the forms, with no mapping table.
+type OwnerNormalizer struct {
+	orgs    []string
+	aliases map[string]string // alias identity to its team, as written
+	users   map[string]string // user identity to their team, as written
+}
+
+// newOwnerNormalizer validates an OwnerConfig.
+// An alias can only belong to one team, and every user needs a team.
+func newOwnerNormalizer(conf OwnerConfig) (*OwnerNormalizer, error) {
+	on := &OwnerNormalizer{
+		aliases: make(map[string]string),
+		users:   make(map[string]string),
+	}
+	for _, org := range conf.Orgs {
+		on.orgs = append(on.orgs, strings.ToLower(strings.Trim(org, "@/ ")))
+	}
+
+	var errs []error
+
+	// Sorted, so the same problems are reported in the same order
+	teams := slices.Sorted(maps.Keys(conf.Aliases))
+
+	for _, team := range teams {
+		id := on.identity(team, "")
+		if id == "" {
+			errs = append(errs, errors.New("aliases: a team needs a name"))
+			continue
+		}
+		for _, alias := range conf.Aliases[team] {
+			aid := on.identity(alias, "")
+			if prev, ok := on.aliases[aid]; ok && on.identity(prev, "") != id {
+				errs = append(errs, fmt.Errorf("aliases: %q is an alias of both %q and %q", alias, prev, team))
+				continue
+			}
+			on.aliases[aid] = team
+		}
+	}
+
+	for user, team := range conf.Users {
+		if on.identity(team, "") == "" {
+			errs = append(errs, fmt.Errorf("users: %q needs a team", user))
+			continue
+		}
+		on.users[on.identity(user, "")] = team
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return on, nil
+}
+
+// Normalize turns any name for an owner into its identity.
+// Teams in org, the repo the owner was found in, are known by the team name alone.
+// A user is replaced by their team, then an alias by its team.
+// The table is checked for the name with its org too, e.g.: an alias of @maroda/platform
+// matches @maroda/platform in a maroda repo, even though it's known there as platform.
+func (on *OwnerNormalizer) Normalize(owner, org string) string {
+	id := on.identity(owner, org)
+	if on == nil {
+		return id
+	}
+
+	full := on.identity(owner, "")
+	lookup := func(table map[string]string, id, full string) (string, bool) {
+		if team, ok := table[full]; ok {
+			return team, true
+		}
+		team, ok := table[id]
+		return team, ok
+	}
+
+	// A team is normalized like any other name, once it's found
+	if team, ok := lookup(on.users, id, full); ok {
+		id, full = on.identity(team, org), on.identity(team, "")
+	}
+	if team, ok := lookup(on.aliases, id, full); ok {
+		id = on.identity(team, org)
+	}
+	return id
+}
+
+// identity reads the form of a name, ignoring case, which neither Backstage nor GitHub cares about:
+//
+//   - group:default/platform-team and user:default/alice are platform-team and alice
+//   - @maroda/platform-team is platform-team in the org, otherwise maroda/platform-team
+//   - @platform/infra/sre is sre in a GitLab repo in the platform/infra group
+//   - @alice is alice, and an email is left as it is
+func (on *OwnerNormalizer) identity(owner, org string) string {
+	id := strings.ToLower(strings.TrimSpace(owner))
+
+	// A Backstage ref, the namespace is optional
+	if kind, rest, ok := strings.Cut(id, ":"); ok && (kind == "group" || kind == "user") {
+		if _, name, ok := strings.Cut(rest, "/"); ok {
+			return name
+		}
+		return rest
+	}
+
+	// A GitHub @user or @org/team
+	if handle, ok := strings.CutPrefix(id, "@"); ok {
+		id = handle
+		if group := strings.ToLower(strings.Trim(org, "/")); strings.Contains(group, "/") {
+			if team, ok := strings.CutPrefix(handle, group+"/"); ok {
+				return team
+			}
+		}
+		if o, team, ok := strings.Cut(handle, "/"); ok && on.knownOrg(o, org) {
+			return team
+		}
+	}
+	return id
+}
+
+// knownOrg is true for the repo's own org, and any org in the table.
+func (on *OwnerNormalizer) knownOrg(o, org string) bool {
+	if o == strings.ToLower(strings.Trim(org, "/")) {
+		return true
+	}
+	return on != nil && slices.Contains(on.orgs, o)
+}
@@ -0,0 +1,86 @@
+package verificat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnerNormalizer_Normalize(t *testing.T) {
+	t.Run("understands every form without a table", func(t *testing.T) {
+		var on *OwnerNormalizer
+
+		formTests := []struct {
+			Owner  string
+			Expect string
+		}{
+			{"group:default/platform-team", "platform-team"},
+			{"Group:Default/Platform-Team", "platform-team"},
+			{"group:platform-team", "platform-team"},
+			{"user:default/alice", "alice"},
+			{"@maroda/platform-team", "platform-team"},
+			{"@octo/platform-team", "octo/platform-team"},
+			{"@alice", "alice"},
+			{"alice@example.com", "alice@example.com"},
+			{"platform-team", "platform-team"},
+		}
+
+		for _, tt := range formTests {
+			assertString(t, on.Normalize(tt.Owner, "maroda"), tt.Expect)
+		}
+
+		// A GitLab subgroup is the org of its repos
+		assertString(t, on.Normalize("@Platform/Infra/SRE", "platform/infra"), "sre")
+		assertString(t, on.Normalize("@platform/sre", "platform/infra"), "platform/sre")
+	})
+
+	t.Run("maps through the table", func(t *testing.T) {
+		on, err := newOwnerNormalizer(OwnerConfig{
+			Orgs:    []string{"@octo"},
+			Aliases: map[string][]string{"group:default/platform-team": {"platform", "@maroda/sre", "infra"}},
+			Users: map[string]string{
+				"alice@example.com": "@maroda/platform-team",
+				"user:default/bob":  "infra",
+				"carol@example.com": "web-team",
+			},
+		})
+		assertNoError(t, err)
+
+		mapTests := []struct {
+			Owner  string
+			Expect string
+		}{
+			{"@octo/platform-team", "platform-team"},
+			{"@maroda/sre", "platform-team"},
+			{"platform", "platform-team"},
+			{"alice@example.com", "platform-team"},
+			{"@bob", "platform-team"},
+			{"carol@example.com", "web-team"},
+			{"@maroda/web-team", "web-team"},
+		}
+
+		for _, tt := range mapTests {
+			assertString(t, on.Normalize(tt.Owner, "maroda"), tt.Expect)
+		}
+	})
+}
+
+func TestNewOwnerNormalizer(t *testing.T) {
+	validationTests := []struct {
+		Name   string
+		Conf   OwnerConfig
+		Expect string
+	}{
+		{"AliasOfTwoTeams", OwnerConfig{Aliases: map[string][]string{"a-team": {"ops"}, "b-team": {"ops"}}}, `"ops" is an alias of both "a-team" and "b-team"`},
+		{"UserWithoutTeam", OwnerConfig{Users: map[string]string{"alice@example.com": ""}}, `"alice@example.com" needs a team`},
+	}
+
+	for _, tt := range validationTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			_, err := newOwnerNormalizer(tt.Conf)
+			assertGotError(t, err)
+			if err != nil && !strings.Contains(err.Error(), tt.Expect) {
+				t.Errorf("error %q does not contain %q", err, tt.Expect)
+			}
+		})
+	}
+}
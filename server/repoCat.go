@@ -0,0 +1,227 @@
+package verificat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// catalogInfoFile is where Backstage expects a repo to describe itself.
+// https://backstage.io/docs/features/software-catalog/descriptor-format
+const catalogInfoFile = "catalog-info.yaml"
+
+// catalogInfo is the part of a Backstage descriptor that Verificat reads.
+// Everything else in the file is ignored.
+type catalogInfo struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Labels      map[string]string `yaml:"labels"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Owner  string `yaml:"owner"`
+		System string `yaml:"system"`
+	} `yaml:"spec"`
+}
+
+// RepoCat reads Services from the catalog-info.yaml in each of their repos,
+// the same descriptors Backstage would read, without Backstage.
+// The repos are the ones the Checklist declares for each Service,
+// a Service without any is read from the repo named after it in Org.
+type RepoCat struct {
+	API    string               // The GitHub API, e.g.: https://api.github.com
+	Org    string               // For a Service with no repos declared
+	File   string               // The descriptor in each repo, defaults to catalog-info.yaml
+	Repos  map[string][]RepoRef // The repos declared for each Service
+	Forges Forges               // The forges repos outside GitHub are on
+	Svc    *SvcConfig           // The Service to read, filled in by ReadSvc
+}
+
+// newRepoCatalog reads Services from their repos, in GitHub or the forge each names, args are all optional:
+// api and org default to the same as OwnerCheck, file to catalog-info.yaml.
+func newRepoCatalog(args map[string]string, repos map[string][]RepoRef, forges Forges) (catalogBackend, error) {
+	if err := checkArgs(args, "domain", "api", "org", "file"); err != nil {
+		return nil, err
+	}
+
+	base := RepoCat{API: ghAPI, Org: ghPreURI, File: catalogInfoFile, Repos: repos, Forges: forges}
+	deprecatedDomain(args)
+	if v, ok := args["api"]; ok {
+		base.API = v
+	}
+	if v, ok := args["org"]; ok {
+		base.Org = v
+	}
+	if v, ok := args["file"]; ok {
+		base.File = v
+	}
+	base.Org = strings.Trim(base.Org, "/")
+	base.File = strings.TrimPrefix(base.File, "/")
+
+	// Each Service gets its own RepoCat to fill in
+	return func(sc *SvcConfig) (Catalog, error) {
+		rc := base
+		rc.Svc = sc
+		return &rc, nil
+	}, nil
+}
+
+// ReadSvc finds the Service in the descriptor of its first repo, by name or by kind:name.
+// The Components of a System are the ones described alongside it.
+// A Service that isn't described there returns SystemNotRecognized.
+func (rc *RepoCat) ReadSvc(ctx context.Context) (string, error) {
+	rc.Svc.Datetime = time.Now().Unix()
+
+	eref, err := ParseEntityRef(rc.Svc.Service)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", SystemNotRecognized, err)
+	}
+
+	ref := rc.reposOf(eref.Name)[0]
+	entries, err := rc.read(ctx, ref)
+	if errors.Is(err, SourceNotFound) {
+		return "", fmt.Errorf("%w: no %s in %s", SystemNotRecognized, rc.File, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	i := slices.IndexFunc(entries, func(e CatalogEntry) bool {
+		return e.Name == eref.Name && (eref.Kind == "" || eref.Kind == e.Kind)
+	})
+	if i < 0 {
+		return "", fmt.Errorf("%w: %s in %s of %s", SystemNotRecognized, rc.Svc.Service, rc.File, ref)
+	}
+
+	rc.Svc.Service = entries[i].Service
+	rc.Svc.Name = entries[i].Name
+	rc.Svc.Owner = entries[i].Owner
+	rc.Svc.Repos = entries[i].Repos
+	if entries[i].Kind == "system" {
+		rc.Svc.Members = systemMembers(entries[i].Name, slices.Values(entries))
+	}
+	slog.Debug("Owner Set", slog.String("Owner", rc.Svc.Owner), slog.Any("Repos", rc.Svc.Repos))
+	return rc.Svc.Owner, nil
+}
+
+// ListSvc lists every Service described in the repos of the Services in the Checklist,
+// of the given kinds, system when none are given.
+// A repo without a descriptor is skipped, any other problem fails the list.
+func (rc *RepoCat) ListSvc(ctx context.Context, kinds ...string) ([]CatalogEntry, error) {
+	if len(kinds) == 0 {
+		kinds = []string{"system"}
+	}
+
+	services := slices.Sorted(maps.Keys(rc.Repos))
+
+	read := make(map[RepoRef]bool)
+	listed := make(map[string]CatalogEntry)
+	for _, service := range services {
+		ref := rc.reposOf(service)[0]
+		if read[ref] {
+			continue
+		}
+		read[ref] = true
+
+		entries, err := rc.read(ctx, ref)
+		if errors.Is(err, SourceNotFound) {
+			slog.Warn("Repo Has No Catalog Info", slog.String("Repo", ref.String()), slog.String("File", rc.File))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if slices.Contains(kinds, entry.Kind) {
+				listed[entry.Name] = entry
+			}
+		}
+	}
+
+	entries := slices.Collect(maps.Values(listed))
+	sortEntries(entries)
+	return entries, nil
+}
+
+// reposOf is the repos declared for a Service, or the one named after it in Org.
+func (rc *RepoCat) reposOf(service string) []RepoRef {
+	if repos := rc.Repos[service]; len(repos) > 0 {
+		return repos
+	}
+	return []RepoRef{{Org: rc.Org, Repo: service}}
+}
+
+// read fetches and parses the descriptor in a repo, on its default branch unless it names one.
+// An entity without its own project-slug annotation lives in the repo it was read from.
+func (rc *RepoCat) read(ctx context.Context, ref RepoRef) ([]CatalogEntry, error) {
+	forge, err := rc.Forges.For(ref, rc.API)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _, err := forge.RepoFile(ctx, ref, rc.File)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseCatalogInfo(body)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing %s in %s, %v", rc.File, ref, err)
+	}
+	for i := range entries {
+		if len(entries[i].Repos) == 0 {
+			entries[i].Repos = []RepoRef{ref}
+		}
+	}
+	return entries, nil
+}
+
+// parseCatalogInfo reads every entity from a Backstage descriptor,
+// which can hold more than one YAML document.
+// Entities without a name, e.g.: a Location, are skipped.
+func parseCatalogInfo(data string) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+
+	dec := yaml.NewDecoder(strings.NewReader(data))
+	for {
+		var ci catalogInfo
+		err := dec.Decode(&ci)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ci.Metadata.Name == "" {
+			continue
+		}
+
+		// A Component names its System as a ref, e.g.: admin or system:default/admin
+		var system string
+		if sref, err := ParseEntityRef(ci.Spec.System); err == nil {
+			system = sref.Name
+		}
+
+		entries = append(entries, CatalogEntry{
+			Service: ci.Metadata.Name,
+			Name:    ci.Metadata.Name,
+			Kind:    strings.ToLower(ci.Kind),
+			Owner:   ci.Spec.Owner,
+			System:  system,
+			Labels:  ci.Metadata.Labels,
+			Repos:   bsRepos(ci.Metadata.Annotations),
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,113 @@
+package verificat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const mockCatalogInfo = `apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  name: admin
+  labels:
+    tier: "1"
+spec:
+  owner: code-owners-admin
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: admin-web
+  annotations:
+    github.com/project-slug: maroda/admin-web
+spec:
+  type: website
+  owner: code-owners-admin
+  system: admin
+---
+apiVersion: backstage.io/v1alpha1
+kind: Location
+spec:
+  targets: [./web/catalog-info.yaml]
+`
+
+func TestParseCatalogInfo(t *testing.T) {
+	got, err := parseCatalogInfo(mockCatalogInfo)
+	assertNoError(t, err)
+
+	assertIDEquals(t, len(got), 2)
+	assertString(t, got[0].Kind, "system")
+	assertString(t, got[0].Owner, "code-owners-admin")
+	assertString(t, got[0].Labels["tier"], "1")
+	assertString(t, got[1].Kind, "component")
+	assertString(t, got[1].Repos[0].String(), "maroda/admin-web")
+	assertString(t, got[1].System, "admin")
+
+	t.Run("returns an error for malformed YAML", func(t *testing.T) {
+		_, err := parseCatalogInfo("kind: [System")
+		assertHasError(t, err)
+	})
+}
+
+func TestRepoCat(t *testing.T) {
+	t.Setenv("GH_TOKEN", "mock-token")
+	gh := makeMockGitHub("main", map[string]string{
+		"/maroda/admin/main/catalog-info.yaml":   mockCatalogInfo,
+		"/maroda/ledger/trunk/catalog-info.yaml": "kind: System\nmetadata: {name: ledger}\nspec: {owner: code-owners-ledger}\n",
+	})
+	defer gh.Close()
+
+	backend, err := newRepoCatalog(map[string]string{"api": gh.URL}, map[string][]RepoRef{
+		"admin":  nil,
+		"ledger": {{Org: "maroda", Repo: "ledger", Branch: "trunk"}},
+		"core":   {{Org: "maroda", Repo: "core", Branch: "main"}},
+	}, nil)
+	assertNoError(t, err)
+
+	open := func(service string) (*SvcConfig, Catalog) {
+		sc := &SvcConfig{Service: service}
+		catalog, err := backend(sc)
+		assertNoError(t, err)
+		return sc, catalog
+	}
+
+	t.Run("reads a service from the repo named after it", func(t *testing.T) {
+		sc, catalog := open("admin")
+		got, err := ReadinessRead(context.Background(), catalog)
+		assertNoError(t, err)
+		assertString(t, got, "code-owners-admin")
+		assertString(t, sc.Repos[0].String(), "maroda/admin")
+		assertIDEquals(t, len(sc.Members), 1)
+		assertString(t, sc.Members[0].Service, "admin-web")
+	})
+
+	t.Run("reads a service from its declared repo", func(t *testing.T) {
+		sc, catalog := open("ledger")
+		got, err := ReadinessRead(context.Background(), catalog)
+		assertNoError(t, err)
+		assertString(t, got, "code-owners-ledger")
+		assertString(t, sc.Repos[0].String(), "maroda/ledger@trunk")
+	})
+
+	t.Run("a repo without catalog-info.yaml is not recognized", func(t *testing.T) {
+		_, catalog := open("core")
+		_, err := ReadinessRead(context.Background(), catalog)
+		assertError(t, err, SystemNotRecognized)
+	})
+
+	t.Run("lists every service described in the repos", func(t *testing.T) {
+		_, catalog := open("")
+		got, err := catalog.ListSvc(context.Background(), "system", "component")
+		assertNoError(t, err)
+
+		var names []string
+		for _, e := range got {
+			names = append(names, e.Name)
+		}
+		if diff := cmp.Diff(names, []string{"admin", "admin-web", "ledger"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
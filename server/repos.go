@@ -0,0 +1,111 @@
+package verificat
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RepoRef is a repository that belongs to a Service, written as forge:org/repo@branch.
+// Branch is optional, without it the repo's default branch is used.
+// Forge is optional too, without it the repo is on GitHub, see Forges.
+// A repo on another forge can be in nested groups, e.g.: gitlab:platform/infra/admin-chart
+type RepoRef struct {
+	Forge  string
+	Org    string // The org, workspace, or group path
+	Repo   string
+	Branch string
+}
+
+// ParseRepoRef reads org/repo or org/repo@branch, optionally with a forge: in front.
+func ParseRepoRef(s string) (RepoRef, error) {
+	var ref RepoRef
+	s = strings.TrimSpace(s)
+
+	slug, branch, hasBranch := strings.Cut(s, "@")
+	if hasBranch && branch == "" {
+		return ref, fmt.Errorf("repo %q has an empty branch after @", s)
+	}
+
+	forge, path, hasForge := strings.Cut(slug, ":")
+	if !hasForge {
+		forge, path = "", slug
+	}
+	if hasForge && (forge == "" || strings.Contains(forge, "/")) {
+		return ref, fmt.Errorf("repo %q must name its forge before the :, e.g.: gitlab:org/repo", s)
+	}
+
+	// Only a repo on a forge with groups can be nested in them, see Forges.nests
+	// A declared forge is checked once the Checklist knows its type.
+	i := strings.LastIndex(path, "/")
+	org, repo := path[:max(i, 0)], path[i+1:]
+	if i < 0 || repo == "" || slices.Contains(strings.Split(org, "/"), "") || (strings.Contains(org, "/") && !canNest(forge)) {
+		return ref, fmt.Errorf("repo %q must be org/repo or org/repo@branch", s)
+	}
+
+	ref = RepoRef{Forge: forge, Org: org, Repo: repo, Branch: branch}
+	return ref, nil
+}
+
+// canNest is false for GitHub, with or without its prefix, and the other built-in forges without groups.
+// Any other prefix is a forge the Checklist declares, which may have them.
+func canNest(forge string) bool {
+	if forge == "" {
+		return false
+	}
+	ft, ok := forgeTypes[forge]
+	return !ok || ft.Declared || ft.Nested
+}
+
+// String is the same forge:org/repo@branch form that ParseRepoRef reads.
+func (rr RepoRef) String() string {
+	s := rr.Org + "/" + rr.Repo
+	if rr.Forge != "" {
+		s = rr.Forge + ":" + s
+	}
+	if rr.Branch != "" {
+		s += "@" + rr.Branch
+	}
+	return s
+}
+
+// MarshalText lets a RepoRef show as org/repo@branch in API results.
+func (rr RepoRef) MarshalText() ([]byte, error) {
+	return []byte(rr.String()), nil
+}
+
+func (rr *RepoRef) UnmarshalText(text []byte) error {
+	ref, err := ParseRepoRef(string(text))
+	if err != nil {
+		return err
+	}
+	*rr = ref
+	return nil
+}
+
+// parseRepoRefs reads a list of org/repo@branch, reporting every one that's malformed.
+func parseRepoRefs(refs []string) ([]RepoRef, error) {
+	var repos []RepoRef
+	var bad []string
+	for _, r := range refs {
+		ref, err := ParseRepoRef(r)
+		if err != nil {
+			bad = append(bad, err.Error())
+			continue
+		}
+		repos = append(repos, ref)
+	}
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(bad, "; "))
+	}
+	return repos, nil
+}
+
+// serviceRepos picks the repos for a Service: the checklist wins over the service catalog.
+// With neither, Checks fall back to the repo named after the Service, see OwnerCheck.
+func serviceRepos(checklist, catalog []RepoRef) []RepoRef {
+	if len(checklist) > 0 {
+		return checklist
+	}
+	return catalog
+}
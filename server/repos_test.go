@@ -0,0 +1,71 @@
+package verificat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRepoRef(t *testing.T) {
+	refTests := []struct {
+		Name   string
+		Ref    string
+		Expect RepoRef
+		Error  bool
+	}{
+		{"OrgRepo", "maroda/verificat", RepoRef{Org: "maroda", Repo: "verificat"}, false},
+		{"WithBranch", "maroda/verificat@develop", RepoRef{Org: "maroda", Repo: "verificat", Branch: "develop"}, false},
+		{"SlashInBranch", "maroda/verificat@release/1.0", RepoRef{Org: "maroda", Repo: "verificat", Branch: "release/1.0"}, false},
+		{"NoOrg", "verificat", RepoRef{}, true},
+		{"EmptyRepo", "maroda/", RepoRef{}, true},
+		{"EmptyBranch", "maroda/verificat@", RepoRef{}, true},
+		{"TooDeep", "maroda/verificat/server", RepoRef{}, true},
+		{"Forge", "gitea:maroda/verificat@main", RepoRef{Forge: "gitea", Org: "maroda", Repo: "verificat", Branch: "main"}, false},
+		{"NestedGroups", "gitlab:platform/infra/admin-chart", RepoRef{Forge: "gitlab", Org: "platform/infra", Repo: "admin-chart"}, false},
+		{"NestedOnDeclaredForge", "work:platform/infra/admin-chart", RepoRef{Forge: "work", Org: "platform/infra", Repo: "admin-chart"}, false},
+		{"NestedOnGitHub", "github:maroda/verificat/server", RepoRef{}, true},
+		{"NestedOnGitea", "gitea:maroda/verificat/server", RepoRef{}, true},
+		{"NestedOnBitbucket", "bitbucket:maroda/verificat/server", RepoRef{}, true},
+		{"EmptyForge", ":maroda/verificat", RepoRef{}, true},
+		{"EmptyGroup", "gitlab:platform//admin-chart", RepoRef{}, true},
+	}
+
+	for _, tt := range refTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := ParseRepoRef(tt.Ref)
+			if tt.Error {
+				assertHasError(t, err)
+				return
+			}
+			assertNoError(t, err)
+			if diff := cmp.Diff(got, tt.Expect); diff != "" {
+				t.Error(diff)
+			}
+			assertString(t, got.String(), tt.Ref)
+		})
+	}
+}
+
+func TestRepoRef_JSON(t *testing.T) {
+	want := []RepoRef{{Org: "maroda", Repo: "verificat", Branch: "main"}}
+
+	data, err := json.Marshal(want)
+	assertNoError(t, err)
+	assertString(t, string(data), `["maroda/verificat@main"]`)
+
+	var got []RepoRef
+	assertNoError(t, json.Unmarshal(data, &got))
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestServiceRepos(t *testing.T) {
+	checklist := []RepoRef{{Org: "maroda", Repo: "checklist"}}
+	catalog := []RepoRef{{Org: "maroda", Repo: "catalog"}}
+
+	assertString(t, serviceRepos(checklist, catalog)[0].Repo, "checklist")
+	assertString(t, serviceRepos(nil, catalog)[0].Repo, "catalog")
+	assertIDEquals(t, len(serviceRepos(nil, nil)), 0)
+}
@@ -0,0 +1,196 @@
+package verificat
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ScoringPolicy turns the results of a run into a Score out of 100.
+// The same policy is used for each principle's SubScore.
+type ScoringPolicy interface {
+	Name() string
+	Score(results []*CheckResult) int
+}
+
+// ScoringConfig selects the ScoringPolicy for a Checklist.
+type ScoringConfig struct {
+	Policy string            `yaml:"policy"` // One of: golf, weighted, capped, percentage
+	Caps   map[Principle]int `yaml:"caps"`   // Only for capped, the most points each principle can cost
+}
+
+// newScoringPolicy validates a ScoringConfig and returns its ScoringPolicy.
+// With no policy configured, golf is used, as it always has been.
+func newScoringPolicy(conf ScoringConfig) (ScoringPolicy, error) {
+	if len(conf.Caps) > 0 && conf.Policy != "capped" {
+		return nil, fmt.Errorf("caps are only used by the capped policy, not %q", conf.Policy)
+	}
+
+	switch conf.Policy {
+	case "", "golf":
+		return &GolfPolicy{}, nil
+	case "weighted":
+		return &WeightedPolicy{}, nil
+	case "percentage":
+		return &PercentagePolicy{}, nil
+	case "capped":
+		if len(conf.Caps) == 0 {
+			return nil, errors.New("the capped policy needs at least one cap")
+		}
+		for p, c := range conf.Caps {
+			if !slices.Contains(Principles, p) {
+				return nil, fmt.Errorf("unknown principle %q in caps, known principles are %v", p, Principles)
+			}
+			if c < 0 {
+				return nil, fmt.Errorf("cap for %q must not be negative, got %d", p, c)
+			}
+		}
+		return &CappedPolicy{Caps: conf.Caps}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring policy %q, known policies are [capped golf percentage weighted]", conf.Policy)
+	}
+}
+
+// GolfPolicy is the original golf game style scoring.
+// Start at 100 and subtract one for each failed Validation and each failed Verification,
+// so a failed Validation costs two points because Verification automatically fails too.
+type GolfPolicy struct{}
+
+func (gp *GolfPolicy) Name() string { return "golf" }
+
+func (gp *GolfPolicy) Score(results []*CheckResult) int {
+	score := 100
+	for _, result := range results {
+		score -= golfDeduction(result)
+	}
+	return max(score, 0)
+}
+
+// WeightedPolicy starts at 100 and subtracts the Weight of each failed Check, once.
+// A failed Validation costs the same as a failed Verification.
+// Weights have no upper bound, so the Score never goes below 0.
+type WeightedPolicy struct{}
+
+func (wp *WeightedPolicy) Name() string { return "weighted" }
+
+func (wp *WeightedPolicy) Score(results []*CheckResult) int {
+	score := 100
+	for _, result := range results {
+		if !result.Passed() {
+			score -= result.Weight
+		}
+	}
+	return max(score, 0)
+}
+
+// CappedPolicy is the WeightedPolicy, but no principle can cost more than its cap.
+// A failed Check is charged against every principle it is tagged with,
+// so its deduction is limited by whichever of those has the least left to give.
+// Principles without a cap are unlimited, and the Score never goes below 0.
+type CappedPolicy struct {
+	Caps map[Principle]int
+}
+
+func (cp *CappedPolicy) Name() string { return "capped" }
+
+func (cp *CappedPolicy) Score(results []*CheckResult) int {
+	// Track what's left of each cap as failures are charged against it
+	remaining := make(map[Principle]int, len(cp.Caps))
+	for p, c := range cp.Caps {
+		remaining[p] = c
+	}
+
+	score := 100
+	for _, result := range results {
+		if result.Passed() {
+			continue
+		}
+
+		deduction := result.Weight
+		for _, p := range result.Principles {
+			if left, ok := remaining[p]; ok && left < deduction {
+				deduction = left
+			}
+		}
+		for _, p := range result.Principles {
+			if _, ok := remaining[p]; ok {
+				remaining[p] -= deduction
+			}
+		}
+		score -= deduction
+	}
+	return max(score, 0)
+}
+
+// PercentagePolicy is the percent of weight from Checks that passed.
+// Unlike the others, this is a percent completed and not a grade.
+type PercentagePolicy struct{}
+
+func (pp *PercentagePolicy) Name() string { return "percentage" }
+
+func (pp *PercentagePolicy) Score(results []*CheckResult) int {
+	var passed, total int
+	for _, result := range results {
+		total += result.Weight
+		if result.Passed() {
+			passed += result.Weight
+		}
+	}
+
+	// Nothing was checked, so nothing failed
+	if total == 0 {
+		return 100
+	}
+	return 100 * passed / total
+}
+
+// golfDeduction is how many points a single result costs.
+// One for a failed Validation, and one for a failed Verification.
+func golfDeduction(cr *CheckResult) int {
+	var points int
+	if !cr.Present {
+		points++
+	}
+	if !cr.Works {
+		points++
+	}
+	return points
+}
+
+// SubScores computes a Score for each of the Eight Principles,
+// using the ScoringPolicy on only the results tagged with that principle.
+// Principles with no Checks tagged are left out, there's nothing to grade.
+func SubScores(results []*CheckResult, policy ScoringPolicy) map[Principle]int {
+	tagged := make(map[Principle][]*CheckResult)
+	for _, result := range results {
+		for _, p := range result.Principles {
+			tagged[p] = append(tagged[p], result)
+		}
+	}
+
+	subs := make(map[Principle]int, len(tagged))
+	for p, pr := range tagged {
+		subs[p] = policy.Score(pr)
+	}
+	return subs
+}
+
+// MemberResult is how a Component of a System scored in the same run.
+type MemberResult struct {
+	Service string
+	Score   int
+	Verdict string
+}
+
+// RollUp makes a System as ready as its least ready Component.
+// The Score is the lowest of its own and its members', and any not-ready member makes it not-ready.
+// SubScore is left as the System's own, members keep their own in the almanac.
+func RollUp(run *RunReturn, members []MemberResult) {
+	for _, m := range members {
+		run.Score = min(run.Score, m.Score)
+		if m.Verdict == VerdictNotReady {
+			run.Verdict = VerdictNotReady
+		}
+	}
+	run.Members = members
+}
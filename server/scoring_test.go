@@ -0,0 +1,149 @@
+package verificat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGolfDeduction(t *testing.T) {
+	deductionTests := []struct {
+		Name   string
+		Result *CheckResult
+		Expect int
+	}{
+		{"Passed", &CheckResult{Present: true, Works: true}, 0},
+		{"FailedVerification", &CheckResult{Present: true}, 1},
+		{"FailedValidation", &CheckResult{}, 2},
+	}
+
+	for _, tt := range deductionTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assertIDEquals(t, golfDeduction(tt.Result), tt.Expect)
+		})
+	}
+}
+
+func TestScoringPolicies(t *testing.T) {
+	// A fixed set of results for every policy to grade:
+	// - docs fails Validation (so Verification too) and weighs 3
+	// - alerts fails Verification and weighs 2
+	// - runbook fails Verification and weighs 4, tagged docs and catastrophe
+	// - uptime passes and weighs 1
+	results := []*CheckResult{
+		{ID: "docs", Principles: []Principle{PrincipleDocs}, Weight: 3},
+		{ID: "alerts", Principles: []Principle{PrincipleMonitoring}, Weight: 2, Present: true},
+		{ID: "runbook", Principles: []Principle{PrincipleDocs, PrincipleCatastrophe}, Weight: 4, Present: true},
+		{ID: "uptime", Principles: []Principle{PrincipleReliability}, Weight: 1, Present: true, Works: true},
+	}
+
+	policyTests := []struct {
+		Name   string
+		Policy ScoringPolicy
+		Expect int
+	}{
+		{"golf", &GolfPolicy{}, 96},
+		{"weighted", &WeightedPolicy{}, 91},
+		// docs is capped at 5: docs costs 3, then runbook is limited to the 2 left
+		{"capped", &CappedPolicy{Caps: map[Principle]int{PrincipleDocs: 5}}, 93},
+		// catastrophe is capped at 0, so runbook costs nothing
+		{"capped", &CappedPolicy{Caps: map[Principle]int{PrincipleCatastrophe: 0}}, 95},
+		// 1 of 10 weight passed
+		{"percentage", &PercentagePolicy{}, 10},
+	}
+
+	for _, tt := range policyTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assertString(t, tt.Policy.Name(), tt.Name)
+			assertIDEquals(t, tt.Policy.Score(results), tt.Expect)
+		})
+	}
+
+	t.Run("a heavy failure never scores below 0", func(t *testing.T) {
+		heavy := []*CheckResult{{ID: "docs", Principles: []Principle{PrincipleDocs}, Weight: 250}}
+		for _, policy := range []ScoringPolicy{&WeightedPolicy{}, &CappedPolicy{Caps: map[Principle]int{PrincipleMonitoring: 1}}} {
+			assertIDEquals(t, policy.Score(heavy), 0)
+		}
+	})
+
+	t.Run("percentage with nothing checked is 100", func(t *testing.T) {
+		assertIDEquals(t, (&PercentagePolicy{}).Score(nil), 100)
+	})
+}
+
+func TestNewScoringPolicy(t *testing.T) {
+	t.Run("golf is the default", func(t *testing.T) {
+		policy, err := newScoringPolicy(ScoringConfig{})
+		assertNoError(t, err)
+		assertString(t, policy.Name(), "golf")
+	})
+
+	errorTests := []struct {
+		Name string
+		Conf ScoringConfig
+	}{
+		{"UnknownPolicy", ScoringConfig{Policy: "bowling"}},
+		{"CapsWithoutCapped", ScoringConfig{Policy: "weighted", Caps: map[Principle]int{PrincipleDocs: 1}}},
+		{"CappedWithoutCaps", ScoringConfig{Policy: "capped"}},
+		{"UnknownPrinciple", ScoringConfig{Policy: "capped", Caps: map[Principle]int{"speed": 1}}},
+		{"NegativeCap", ScoringConfig{Policy: "capped", Caps: map[Principle]int{PrincipleDocs: -1}}},
+	}
+
+	for _, tt := range errorTests {
+		t.Run("refuses "+tt.Name, func(t *testing.T) {
+			_, err := newScoringPolicy(tt.Conf)
+			assertGotError(t, err)
+		})
+	}
+}
+
+func TestSubScores(t *testing.T) {
+	t.Run("each principle is scored from its own checks", func(t *testing.T) {
+		results := []*CheckResult{
+			{Principles: []Principle{PrincipleDocs, PrincipleCatastrophe}, Present: true},
+			{Principles: []Principle{PrincipleDocs}},
+			{Principles: []Principle{PrincipleMonitoring}, Present: true, Works: true},
+		}
+
+		got := SubScores(results, &GolfPolicy{})
+		want := map[Principle]int{
+			PrincipleDocs:        97,
+			PrincipleCatastrophe: 99,
+			PrincipleMonitoring:  100,
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("no results leaves every principle out", func(t *testing.T) {
+		assertIDEquals(t, len(SubScores(nil, &GolfPolicy{})), 0)
+	})
+}
+
+// A System is as ready as its least ready Component
+func TestRollUp(t *testing.T) {
+	rollTests := []struct {
+		Name    string
+		Members []MemberResult
+		Score   int
+		Verdict string
+	}{
+		{"NoMembers", nil, 98, VerdictReady},
+		{"BetterMembers", []MemberResult{{Score: 100, Verdict: VerdictReady}}, 98, VerdictReady},
+		{"WorseMember", []MemberResult{{Score: 100, Verdict: VerdictReady}, {Score: 95, Verdict: VerdictReady}}, 95, VerdictReady},
+		{"NotReadyMember", []MemberResult{{Score: 99, Verdict: VerdictNotReady}}, 98, VerdictNotReady},
+	}
+
+	for _, tt := range rollTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			run := &RunReturn{Score: 98, Verdict: VerdictReady, SubScore: map[Principle]int{PrincipleDocs: 98}}
+			RollUp(run, tt.Members)
+
+			assertIDEquals(t, run.Score, tt.Score)
+			assertString(t, run.Verdict, tt.Verdict)
+			assertIDEquals(t, run.SubScore[PrincipleDocs], 98)
+			assertIDEquals(t, len(run.Members), len(tt.Members))
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package verificat
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,33 +33,58 @@ const (
 // then the score is 0, and this value remains 100.
 // If the checklist comes back as false,
 // then the score is 1, and this value becomes 99.
+// The Verdict is ready unless a Required check failed.
+// SubScore is the same kind of score for each of the Eight Principles.
 type WMService struct {
-	Name   string // Service Name
-	LastID int    // The last test ID
-	Score  int    // The current score (100 - score)
+	Name     string            // Service Name
+	LastID   int               // The last test ID
+	Score    int               // The current score (100 - score)
+	Policy   string            // The ScoringPolicy that produced Score
+	Verdict  string            // The current verdict (ready or not-ready)
+	SubScore map[Principle]int // The current score for each principle checked
+	SRO      *SROStatus        `json:",omitempty"` // Measured on read against the Objectives, never stored
+	Waivers  []Waiver          `json:",omitempty"` // Accepted failures, including expired ones
 }
 
 type ServiceStore interface {
-	GetTriggerID(name string) int     // Retrieve the count of tests done
-	TriggerID(name string, score int) // The current run ID, its score
-	GetAlmanac() Almanac              // A collection of all services and their scores
+	GetTriggerID(name string) int          // Retrieve the count of tests done
+	TriggerID(name string, run *RunReturn) // The current run ID, its score and verdict
+	GetAlmanac() Almanac                   // A collection of all services and their scores
+	AddWaiver(name string, w Waiver) error // Accept a failure for a known service
+	GetWaivers(name string) []Waiver       // Every waiver for a service, including expired ones
 }
 
 // VerificationServ is the main brain,
 // serving up http and collecting stats
 // connected to a specific data store
 type VerificationServ struct {
-	stats  *vo.StatsInternal // Prometheus metrics
-	store  ServiceStore      // The Almanac service database
-	tracer trace.Tracer      // otel tracer
+	stats   *vo.StatsInternal    // Prometheus metrics
+	store   ServiceStore         // The Almanac service database
+	checks  *Registry            // The Production Readiness Checklist
+	policy  ScoringPolicy        // How the Checklist is scored
+	sros    *Objectives          // Service Readiness Objectives for each tier
+	repos   map[string][]RepoRef // The repos declared for each Service
+	catalog catalogBackend       // The service catalog Services are read from
+	tracer  trace.Tracer         // otel tracer
 	http.Handler
 }
 
 // NewVerificationServ initiates the HTTP service and internal stats with prometheus
-func NewVerificationServ(store ServiceStore) *VerificationServ {
+// The Checklist declares which Checks are run, if nil the DefaultChecklist is used.
+func NewVerificationServ(store ServiceStore, cl *Checklist) *VerificationServ {
+	if cl == nil {
+		cl = DefaultChecklist()
+	}
+
 	v := new(VerificationServ)
 	v.store = store
+	v.checks = cl.Registry()
+	v.policy = cl.Policy()
+	v.sros = &cl.Objectives
+	v.repos = cl.Repos()
+	v.catalog = cl.OpenCatalog
 	v.stats = vo.NewStatsInternal()
+	watchGitHubQuota(v.stats.RecGitHubQuota)
 	v.tracer = otel.Tracer("verification-serv")
 
 	// This will be assigned to the http.Handler in PlayerServer
@@ -67,6 +97,10 @@ func NewVerificationServ(store ServiceStore) *VerificationServ {
 	router.Handle("/healthz", http.HandlerFunc(v.healthzHandler))
 	router.Handle("/v0/almanac", http.HandlerFunc(v.almanacHandler))
 	router.Handle("/v0/", http.HandlerFunc(v.servicesHandler))
+	router.Handle("/v1/checks", http.HandlerFunc(v.checksHandler))
+	router.Handle("/v1/objectives", http.HandlerFunc(v.objectivesHandler))
+	router.Handle("/v1/waivers/", http.HandlerFunc(v.waiversHandler))
+	router.Handle("/v1/sweep", http.HandlerFunc(v.sweepHandler))
 	router.Handle("/", http.HandlerFunc(v.homeHandler))
 
 	v.Handler = router
@@ -112,13 +146,18 @@ func (v *VerificationServ) homeHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Create a full dataset to work with
 	// This is where BuildSVG needs to operate first
-	currAlmanac := v.store.GetAlmanac()
+	currAlmanac := v.sros.EvaluateAlmanac(v.store.GetAlmanac())
 	aWeb := &AlmanacWeb{
 		Title:     "Verificat | Production Readiness Scores",
 		Content:   BuildSVG(&currAlmanac, sc),
 		FullScore: currAlmanac,
 	}
 
+	// Only show the SRO headline when there's something measured
+	if summary := v.sros.Summary(currAlmanac); summary.Measured > 0 {
+		aWeb.Objectives = summary
+	}
+
 	if err := RenderWeb(w, aWeb, htmlTemplates, targetDocTmpl); err != nil {
 		slog.Error("Page could not be rendered", slog.Any("Error", err))
 	}
@@ -144,7 +183,7 @@ func (v *VerificationServ) almanacHandler(w http.ResponseWriter, r *http.Request
 
 	// Write response
 	w.Header().Set("content-type", jsonContentType)
-	json.NewEncoder(w).Encode(v.store.GetAlmanac())
+	json.NewEncoder(w).Encode(v.sros.EvaluateAlmanac(v.store.GetAlmanac()))
 
 	// Prometheus
 	methodString := r.Method + ":" + r.RequestURI
@@ -158,6 +197,60 @@ func (v *VerificationServ) almanacHandler(w http.ResponseWriter, r *http.Request
 	)
 }
 
+// List registered checks handler
+// Return the ID, description, and principles of every Check in a run.
+func (v *VerificationServ) checksHandler(w http.ResponseWriter, r *http.Request) {
+	// OpenTelemetry
+	ctx := r.Context()
+	user := os.Getuid()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("user.id", user))
+	ctx, span = v.tracer.Start(ctx, "checksHandler")
+	defer span.End()
+
+	// Write response
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(v.checks.Info())
+
+	// Prometheus
+	methodString := r.Method + ":" + r.RequestURI
+	v.stats.RecWWW("200", methodString)
+
+	slog.Info("Checks API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// Service Readiness Objectives handler
+// Return the percent of services meeting the SRO for their tier.
+func (v *VerificationServ) objectivesHandler(w http.ResponseWriter, r *http.Request) {
+	// OpenTelemetry
+	ctx := r.Context()
+	user := os.Getuid()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("user.id", user))
+	ctx, span = v.tracer.Start(ctx, "objectivesHandler")
+	defer span.End()
+
+	// Write response
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(v.sros.Summary(v.store.GetAlmanac()))
+
+	// Prometheus
+	methodString := r.Method + ":" + r.RequestURI
+	v.stats.RecWWW("200", methodString)
+
+	slog.Info("Objectives API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
 // API for service tests handler
 // Version 0 (/v0/<SERVICE>)
 func (v *VerificationServ) servicesHandler(w http.ResponseWriter, r *http.Request) {
@@ -176,8 +269,9 @@ func (v *VerificationServ) servicesHandler(w http.ResponseWriter, r *http.Reques
 	// These methods on VerificationServ can pass the handler interfaces around
 	switch r.Method {
 	case http.MethodPost:
-		// Kick off the test and display the results
-		v.runVerification(w, service)
+		// Kick off the test and display the results.
+		// The request context cancels the run if the client goes away.
+		v.runVerification(ctx, w, service, isDryRun(r))
 	case http.MethodGet:
 		// Get last session ID from the database.
 		v.showLastID(w, service)
@@ -195,6 +289,183 @@ func (v *VerificationServ) servicesHandler(w http.ResponseWriter, r *http.Reques
 	)
 }
 
+// API for waivers handler
+// Version 1 (/v1/waivers/<SERVICE>)
+func (v *VerificationServ) waiversHandler(w http.ResponseWriter, r *http.Request) {
+	// OpenTelemetry
+	ctx := r.Context()
+	user := os.Getuid()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("user.id", user))
+	ctx, span = v.tracer.Start(ctx, "waiversHandler")
+	defer span.End()
+
+	service := strings.TrimPrefix(r.URL.Path, "/v1/waivers/")
+	status := http.StatusOK
+
+	switch r.Method {
+	case http.MethodPost:
+		status = v.createWaiver(w, r, service)
+	case http.MethodGet:
+		v.listWaivers(w, service)
+	default:
+		status = http.StatusMethodNotAllowed
+		w.WriteHeader(status)
+	}
+
+	// Prometheus
+	methodString := r.Method + ":" + r.RequestURI
+	v.stats.RecWWW(strconv.Itoa(status), methodString)
+
+	slog.Info("Waivers API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// API for sweeping the whole catalog handler
+// Version 1 (/v1/sweep?owner=<OWNER>&label=<KEY>=<VALUE>&components=true&dryrun=true)
+// Every parameter is optional, without any every System in the catalog is verified.
+func (v *VerificationServ) sweepHandler(w http.ResponseWriter, r *http.Request) {
+	// OpenTelemetry
+	ctx := r.Context()
+	user := os.Getuid()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("user.id", user))
+	ctx, span = v.tracer.Start(ctx, "sweepHandler")
+	defer span.End()
+
+	status := v.runSweep(ctx, w, r)
+
+	// Prometheus
+	methodString := r.Method + ":" + r.RequestURI
+	v.stats.RecWWW(strconv.Itoa(status), methodString)
+
+	slog.Info("Sweep API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// runSweep verifies every Service the request selects, returning the HTTP status it wrote.
+func (v *VerificationServ) runSweep(ctx context.Context, w http.ResponseWriter, r *http.Request) int {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return http.StatusMethodNotAllowed
+	}
+
+	query := r.URL.Query()
+	sel, err := ParseSelector(query.Get("owner"), query["label"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+
+	// Systems unless kinds are given, components=true adds Components to either
+	kinds := query["kind"]
+	if len(kinds) == 0 {
+		kinds = []string{"system"}
+	}
+	if components, _ := strconv.ParseBool(query.Get("components")); components {
+		kinds = append(kinds, "component")
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(entityKinds, kind) {
+			http.Error(w, fmt.Sprintf("kind %q is not supported, supported kinds are %v", kind, entityKinds), http.StatusBadRequest)
+			return http.StatusBadRequest
+		}
+	}
+
+	catalog, err := v.catalog(new(SvcConfig))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+
+	report, err := v.sweep(ctx, catalog, sel, kinds, isDryRun(r))
+	if err != nil {
+		slog.Error("Sweep Failed", slog.Any("Error", err))
+		http.Error(w, fmt.Sprintf("The service catalog could not be listed, %v", err), http.StatusBadGateway)
+		return http.StatusBadGateway
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(report)
+	return http.StatusOK
+}
+
+// createWaiver stores a new Waiver from the JSON request body.
+func (v *VerificationServ) createWaiver(w http.ResponseWriter, r *http.Request, service string) int {
+	var waiver Waiver
+	if err := json.NewDecoder(r.Body).Decode(&waiver); err != nil {
+		http.Error(w, fmt.Sprintf("could not read waiver, %v", err), http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+
+	now := time.Now()
+	if err := waiver.Validate(now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+
+	// A Waiver for a Check that isn't registered would never apply
+	if ids := v.checks.IDs(); !slices.Contains(ids, waiver.Check) {
+		http.Error(w, fmt.Sprintf("unknown Check %q, known checks are %v", waiver.Check, ids), http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+	waiver.Created = now
+
+	if err := v.store.AddWaiver(service, waiver); err != nil {
+		slog.Error("Waiver not stored", slog.String("Service", service), slog.Any("Error", err))
+		if errors.Is(err, ServiceNotFound) {
+			http.Error(w, fmt.Sprintf("No record found for %+v, waivers can only be added to known services.", service), http.StatusNotFound)
+			return http.StatusNotFound
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+
+	slog.Info("Waiver Added",
+		slog.String("Service", service),
+		slog.String("Check", waiver.Check),
+		slog.String("Approver", waiver.Approver),
+		slog.Time("Expires", waiver.Expires),
+	)
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(waiver)
+	return http.StatusCreated
+}
+
+// listWaivers shows every Waiver for a service, and whether it still applies.
+func (v *VerificationServ) listWaivers(w http.ResponseWriter, service string) {
+	now := time.Now()
+	waivers := v.store.GetWaivers(service)
+	statuses := make([]WaiverStatus, 0, len(waivers))
+	for _, waiver := range waivers {
+		statuses = append(statuses, WaiverStatus{Waiver: waiver, Active: waiver.Active(now)})
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// isDryRun is true when the request asks not to record its results,
+// with either ?dryrun=true or the X-Verificat-Dry-Run: true header.
+func isDryRun(r *http.Request) bool {
+	for _, v := range []string{r.URL.Query().Get("dryrun"), r.Header.Get("X-Verificat-Dry-Run")} {
+		if dry, err := strconv.ParseBool(v); err == nil && dry {
+			return true
+		}
+	}
+	return false
+}
+
 // showLastID will display the ID of the most recent verification run for this service.
 func (v *VerificationServ) showLastID(w http.ResponseWriter, service string) {
 	// GetTriggerID is a method available through the interface
@@ -209,50 +480,34 @@ func (v *VerificationServ) showLastID(w http.ResponseWriter, service string) {
 }
 
 // runVerification. Takes a passed configuration and launches testing.
-func (v *VerificationServ) runVerification(w http.ResponseWriter, service string) {
+// Upstream requests are cancelled along with ctx, e.g. on disconnect or shutdown.
+// A dry run returns the results without recording anything in the almanac.
+func (v *VerificationServ) runVerification(ctx context.Context, w http.ResponseWriter, service string, dryRun bool) {
 	start := time.Now()
-	w.WriteHeader(http.StatusAccepted)
-
-	var err error
-	envVar := "BACKSTAGE"
-	url := fillEnvVar(envVar)
 
-	// if there's no EnvVar, log an error and go no further
-	if url == "ENOENT" {
-		slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", url))
+	// if there's no catalog to read, log an error and go no further
+	svcconf := &SvcConfig{Service: service}
+	catalog, err := v.catalog(svcconf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Create a data object for the configuration.
-	svcconf := &SvcConfig{URL: url, Service: service}
-
-	// Read the SVC and get the "owner" string back
+	// Read the SVC from the service catalog and get the "owner" string back
 	// We don't need a return, it updates the struct
-	/*
-
-		ReadinessRead currently circumnavigates the backstage code and returns a static value.
-
-	*/
-	_, err = ReadinessRead(svcconf)
+	_, err = ReadinessRead(ctx, catalog)
 	if err != nil {
-		slog.Error("ReadinessRead Failed", slog.Any("Error", err))
-	} else {
-		// ReadinessDisplay expects an interface with this struct
-		// These values have been filled in by ReadinessRead() above
-		// Score is initialized to 100 each time,
-		//	then decremented on each failed test
-		//	that is handled by ReadinessDisplay.
-		stests := &SvcTestDB{Datetime: svcconf.Datetime, Owner: svcconf.Owner, Score: 100}
-
-		// Send test metadata to ReadinessDisplay, which launches tests and displays the results.
-		// w == http.ResponseWriter, which satisfies io.Writer
-		err = ReadinessDisplay(stests, service, w)
-		if err != nil {
-			slog.Error("ReadinessDisplay Failed", slog.Any("Error", err))
+		slog.Error("ReadinessRead Failed", slog.String("Service", service), slog.Any("Error", err))
+		if errors.Is(err, SystemNotRecognized) {
+			http.Error(w, fmt.Sprintf("No System found in the service catalog for %+v.", service), http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("The service catalog could not be read for %+v, %v", service, err), http.StatusBadGateway)
 		}
+	} else {
+		w.WriteHeader(http.StatusAccepted)
 
-		// Initiate the TriggerID sequence that is used to set WMService.Score in the database.
-		v.store.TriggerID(service, stests.Score)
+		// w == http.ResponseWriter, which satisfies io.Writer
+		v.verify(ctx, svcconf, dryRun, w)
 	}
 
 	elapsed := time.Since(start).Seconds()
@@ -260,3 +515,63 @@ func (v *VerificationServ) runVerification(w http.ResponseWriter, service string
 	v.stats.RecWWW("200", methodString)
 	v.stats.PollTimer.Observe(elapsed)
 }
+
+// verify runs every Check against a Service that has been read from the catalog,
+// writing the results to w and recording them in the almanac unless it's a dry run.
+// A System's Components are verified first, and its Score is rolled up from theirs.
+func (v *VerificationServ) verify(ctx context.Context, svcconf *SvcConfig, dryRun bool, w io.Writer) *RunReturn {
+	service := svcconf.Service
+
+	var members []MemberResult
+	for _, m := range svcconf.Members {
+		mconf := &SvcConfig{Service: m.Service, Name: m.Name, Datetime: svcconf.Datetime, Owner: m.Owner, Repos: m.Repos}
+		mrun := v.verify(ctx, mconf, dryRun, io.Discard)
+		members = append(members, MemberResult{Service: m.Service, Score: mrun.Score, Verdict: mrun.Verdict})
+	}
+
+	// ReadinessDisplay expects an interface with this struct
+	// These values have been filled in by ReadinessRead()
+	// Score is initialized to 100 each time,
+	//	then the ScoringPolicy grades the results
+	//	that are handled by ReadinessDisplay.
+	// Waivers are looked up fresh each run, so an expired one counts again.
+	stests := &SvcTestDB{
+		Name:     svcconf.entityName(),
+		Datetime: svcconf.Datetime,
+		Owner:    svcconf.Owner,
+		Score:    100,
+		Checks:   v.checks,
+		Policy:   v.policy,
+		Waivers:  v.store.GetWaivers(service),
+		Repos:    serviceRepos(v.repos[svcconf.entityName()], svcconf.Repos),
+		Members:  members,
+		DryRun:   dryRun,
+	}
+
+	// Send test metadata to ReadinessDisplay, which launches tests and displays the results.
+	run, err := ReadinessDisplay(ctx, stests, service, w)
+	if err != nil {
+		slog.Error("ReadinessDisplay Failed", slog.Any("Error", err))
+	}
+
+	// Count every result by status, errors and skips included
+	for _, result := range run.Results {
+		v.stats.RecCheck(result.ID, string(result.Status))
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		// A cancelled run is incomplete, its failures say nothing about the service,
+		// so it is not recorded and the last good Score stands.
+		slog.Warn("Verification Cancelled", slog.String("Service", service), slog.Any("Error", ctx.Err()))
+	case dryRun:
+		// A dry run leaves LastID, Score, and everything else in the almanac as it was
+		slog.Info("Dry Run Not Recorded", slog.String("Service", service), slog.Int("Score", run.Score))
+	default:
+		// Initiate the TriggerID sequence that is used to set WMService.Score in the database.
+		// A Service that isn't in the almanac yet is added.
+		v.store.TriggerID(service, run)
+	}
+
+	return run
+}
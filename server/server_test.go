@@ -4,10 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // This is a data stub for mocking activities on the server
@@ -23,7 +28,7 @@ func (s *StubServiceStore) GetTriggerID(name string) int {
 	return lastID
 }
 
-func (s *StubServiceStore) TriggerID(name string, score int) {
+func (s *StubServiceStore) TriggerID(name string, run *RunReturn) {
 	s.verifyCalls = append(s.verifyCalls, name)
 }
 
@@ -31,6 +36,22 @@ func (s *StubServiceStore) GetAlmanac() Almanac {
 	return s.almanac
 }
 
+func (s *StubServiceStore) AddWaiver(name string, w Waiver) error {
+	service := Almanac(s.almanac).Find(name)
+	if service == nil {
+		return ServiceNotFound
+	}
+	service.Waivers = append(service.Waivers, w)
+	return nil
+}
+
+func (s *StubServiceStore) GetWaivers(name string) []Waiver {
+	if service := Almanac(s.almanac).Find(name); service != nil {
+		return service.Waivers
+	}
+	return nil
+}
+
 // Test /almanac endpoint with JSON output
 func TestAlmanac(t *testing.T) {
 
@@ -38,13 +59,13 @@ func TestAlmanac(t *testing.T) {
 		// Can the Almanac return three values?
 		// name, lastid, score
 		wantedAlmanac := []WMService{
-			{"svcA", 3, 50},
-			{"svcB", 5, 60},
-			{"svcC", 8, 70},
+			{Name: "svcA", LastID: 3, Score: 50},
+			{Name: "svcB", LastID: 5, Score: 60},
+			{Name: "svcC", LastID: 8, Score: 70},
 		}
 
 		store := StubServiceStore{nil, nil, wantedAlmanac}
-		server := NewVerificationServ(&store)
+		server := NewVerificationServ(&store, DefaultChecklist())
 
 		request := newAlmanacRequest()
 		response := httptest.NewRecorder()
@@ -69,7 +90,7 @@ func TestGETServices(t *testing.T) {
 		nil, nil,
 	}
 	// A new struct with an internal reference to the interface
-	server := NewVerificationServ(&store)
+	server := NewVerificationServ(&store, DefaultChecklist())
 
 	t.Run("returns verificat's LastID", func(t *testing.T) {
 		service := "verificat"
@@ -112,7 +133,7 @@ func TestHealthZ(t *testing.T) {
 		map[string]int{},
 		nil, nil,
 	}
-	server := NewVerificationServ(&store)
+	server := NewVerificationServ(&store, DefaultChecklist())
 
 	t.Run("HealthZ endpoint response is 'ok'", func(t *testing.T) {
 		request := newHealthzReq()
@@ -138,7 +159,7 @@ func TestHomeHandler(t *testing.T) {
 		map[string]int{},
 		nil, nil,
 	}
-	server := NewVerificationServ(&store)
+	server := NewVerificationServ(&store, DefaultChecklist())
 
 	t.Run("Homepage returns 200 OK", func(t *testing.T) {
 		request := newHomeReq()
@@ -150,43 +171,457 @@ func TestHomeHandler(t *testing.T) {
 	})
 }
 
-/*
-// Integration: Backstage POST endpoint
-func TestStoreIDs(t *testing.T) {
+// checks endpoint
+func TestChecksHandler(t *testing.T) {
 	store := StubServiceStore{
 		map[string]int{},
 		nil, nil,
 	}
-	server := NewVerificationServ(&store)
+	server := NewVerificationServ(&store, DefaultChecklist())
 
-	// Use a real service - like /verificat/ - to check readiness with the Backstage API
-	t.Run("Integration: Backstage returns 200 on POST", func(t *testing.T) {
-		service := "verificat"
-		request := newPostIDReq(service)
+	t.Run("lists registered checks as JSON", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/v1/checks", nil)
 		response := httptest.NewRecorder()
 
 		server.ServeHTTP(response, request)
 
+		var got []CheckInfo
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into slice of CheckInfo, '%v'", err)
+		}
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+		if !reflect.DeepEqual(got, DefaultChecklist().Registry().Info()) {
+			t.Errorf("Checks returns %v want %v", got, DefaultChecklist().Registry().Info())
+		}
+	})
+}
+
+// objectives endpoint and SRO in the almanac
+func TestObjectivesHandler(t *testing.T) {
+	store := StubServiceStore{nil, nil, []WMService{
+		{Name: "admin", LastID: 3, Score: 98, Verdict: VerdictReady},
+		{Name: "core", LastID: 5, Score: 96, Verdict: VerdictReady},
+	}}
+	cl, err := ParseChecklist([]byte(`
+checks: [{id: owner, type: owner}]
+objectives:
+  tiers:
+    tier-1: {minScore: 97, ready: true}
+services:
+  admin: {tier: tier-1}
+  core: {tier: tier-1}
+`))
+	assertNoError(t, err)
+	server := NewVerificationServ(&store, cl)
+
+	t.Run("returns the percent of services meeting their SRO", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/v1/objectives", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got SROSummary
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into SROSummary, '%v'", err)
+		}
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+		if !reflect.DeepEqual(got, SROSummary{Services: 2, Measured: 2, Met: 1, Percent: 50}) {
+			t.Errorf("Objectives returns %+v", got)
+		}
+	})
+
+	t.Run("almanac includes the SRO for each service", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newAlmanacRequest())
+
+		got := getAlmanacFromResponse(t, response.Body)
+		assertBool(t, got[0].SRO.Met, true)
+		assertIDEquals(t, got[1].SRO.Margin, -1)
+	})
+}
+
+// POST endpoint, with GitHub mocked
+func TestPOSTServices(t *testing.T) {
+	cl := mockUpstreams(t)
+
+	t.Run("records the run in the almanac", func(t *testing.T) {
+		store := StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(&store, cl)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newPostIDReq("admin"))
+
+		// admin-web is a Component of admin, it's verified and recorded first
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if !reflect.DeepEqual(store.verifyCalls, []string{"component:default/admin-web", "admin"}) {
+			t.Errorf("got verified %v", store.verifyCalls)
+		}
+
+		var got RunReturn
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into RunReturn, '%v'", err)
+		}
+		assertIDEquals(t, len(got.Members), 1)
+		assertString(t, got.Members[0].Service, "component:default/admin-web")
+	})
+
+	refTests := []struct {
+		Name   string
+		Ref    string
+		Expect string
+	}{
+		{"Component", "component:admin-web", "component:default/admin-web"},
+		{"FullRef", "component:default/admin-web", "component:default/admin-web"},
+		{"APIInAnotherNamespace", "api:payments/ledger-api", "api:payments/ledger-api"},
+		{"SystemInAnotherNamespace", "system:payments/ledger", "system:payments/ledger"},
+	}
+
+	for _, tt := range refTests {
+		t.Run("records a "+tt.Name+" by its entity ref", func(t *testing.T) {
+			store := StubServiceStore{map[string]int{}, nil, nil}
+			server := NewVerificationServ(&store, cl)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, newPostIDReq(tt.Ref))
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+			if !reflect.DeepEqual(store.verifyCalls, []string{tt.Expect}) {
+				t.Errorf("got verified %v, want [%s]", store.verifyCalls, tt.Expect)
+			}
+		})
+	}
+
+	repoTests := []struct {
+		Name   string
+		Ref    string
+		Expect []string
+	}{
+		{"Component", "component:admin-web", []string{"admin-web"}},
+		{"APIInAnotherNamespace", "api:payments/ledger-api", []string{"ledger-api"}},
+		{"SystemWithComponents", "admin", []string{"admin-api", "admin-web"}},
+	}
+
+	for _, tt := range repoTests {
+		t.Run("reads the repo named after a "+tt.Name, func(t *testing.T) {
+			cl, reads := mockUpstreamsReading(t, "{}")
+			store := StubServiceStore{map[string]int{}, nil, nil}
+			server := NewVerificationServ(&store, cl)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, newPostIDReq(tt.Ref))
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+			if !reflect.DeepEqual(reads.List(), tt.Expect) {
+				t.Errorf("got repos read %v, want %v", reads.List(), tt.Expect)
+			}
+		})
+	}
+
+	t.Run("returns 404 for an unsupported kind", func(t *testing.T) {
+		store := StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(&store, cl)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newPostIDReq("group:sre"))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("returns 404 for a System Backstage doesn't know", func(t *testing.T) {
+		store := StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(&store, cl)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newPostIDReq("Mattic"))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		assertIDEquals(t, len(store.verifyCalls), 0)
+	})
+
+	t.Run("reads the service from an inventory file", func(t *testing.T) {
+		cl := mockUpstreamsWith(t, `{type: file, args: {path: "`+writeInventory(t, mockInventory)+`"}}`)
+		t.Setenv("BACKSTAGE", "http://127.0.0.1:1")
+		store := StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(&store, cl)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newPostIDReq("admin"))
+
 		assertStatus(t, response.Code, http.StatusAccepted)
+		if !reflect.DeepEqual(store.verifyCalls, []string{"admin-web", "admin"}) {
+			t.Errorf("got verified %v", store.verifyCalls)
+		}
+	})
+
+	t.Run("returns 502 when Backstage can't be read", func(t *testing.T) {
+		t.Setenv("BACKSTAGE", "http://127.0.0.1:1")
+		store := StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(&store, cl)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, newPostIDReq("admin"))
+
+		assertStatus(t, response.Code, http.StatusBadGateway)
+	})
+
+	dryRunTests := []struct {
+		Name    string
+		Request func() *http.Request
+	}{
+		{"QueryParameter", func() *http.Request {
+			req, _ := http.NewRequest(http.MethodPost, "/v0/admin?dryrun=true", nil)
+			return req
+		}},
+		{"Header", func() *http.Request {
+			req := newPostIDReq("admin")
+			req.Header.Set("X-Verificat-Dry-Run", "true")
+			return req
+		}},
+	}
+
+	for _, tt := range dryRunTests {
+		t.Run("dry run with "+tt.Name+" is not recorded", func(t *testing.T) {
+			store := StubServiceStore{map[string]int{}, nil, nil}
+			server := NewVerificationServ(&store, cl)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, tt.Request())
+
+			var got RunReturn
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatalf("Unable to parse response from server into RunReturn, '%v'", err)
+			}
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+			assertIDEquals(t, len(store.verifyCalls), 0)
+			assertBool(t, got.DryRun, true)
+			assertString(t, string(got.Results[0].Status), string(StatusPass))
+		})
+	}
+}
+
+// sweep endpoint
+func TestSweepHandler(t *testing.T) {
+	cl := mockUpstreams(t)
+
+	sweep := func(t testing.TB, store *StubServiceStore, method, query string) (*httptest.ResponseRecorder, SweepReport) {
+		t.Helper()
+		server := NewVerificationServ(store, cl)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/v1/sweep"+query, nil)
+		server.ServeHTTP(response, req)
+
+		var report SweepReport
+		if response.Code == http.StatusOK {
+			if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+				t.Fatalf("Unable to parse response from server into SweepReport, '%v'", err)
+			}
+		}
+		return response, report
+	}
+
+	// Systems are verified with their Components, admin-web is part of admin
+	selectTests := []struct {
+		Name     string
+		Query    string
+		Selected int
+		Expect   []string
+	}{
+		{"EverySystem", "", 3, []string{"ad-server", "component:default/admin-web", "admin", "core"}},
+		{"WithComponents", "?components=true", 4, []string{"ad-server", "component:default/admin-web", "admin", "core"}},
+		{"ByOwner", "?owner=code-owners-admin", 1, []string{"component:default/admin-web", "admin"}},
+		{"ByLabel", "?label=tier=2&components=true", 1, []string{"component:default/admin-web"}},
+		{"ByKind", "?kind=component", 1, []string{"component:default/admin-web"}},
+		{"NoMatch", "?owner=nobody", 0, nil},
+	}
 
-		if len(store.verifyCalls) != 1 {
-			t.Errorf("got %d calls to TriggerID want %d", len(store.verifyCalls), 1)
+	for _, tt := range selectTests {
+		t.Run("verifies and records "+tt.Name, func(t *testing.T) {
+			store := &StubServiceStore{map[string]int{}, nil, nil}
+			response, report := sweep(t, store, http.MethodPost, tt.Query)
+
+			assertStatus(t, response.Code, http.StatusOK)
+			assertIDEquals(t, report.Catalog, 4)
+			assertIDEquals(t, report.Selected, tt.Selected)
+			if !reflect.DeepEqual(store.verifyCalls, tt.Expect) {
+				t.Errorf("got verified %v, want %v", store.verifyCalls, tt.Expect)
+			}
+		})
+	}
+
+	t.Run("reports the score of every service", func(t *testing.T) {
+		store := &StubServiceStore{map[string]int{}, nil, nil}
+		_, report := sweep(t, store, http.MethodPost, "?owner=code-owners-admin")
+
+		assertIDEquals(t, len(report.Verified), 2)
+		assertString(t, report.Verified[0].Kind, "component")
+		assertString(t, report.Verified[1].Service, "admin")
+		assertString(t, report.Verified[1].Kind, "system")
+		assertIDEquals(t, report.Verified[1].Score, 100)
+	})
+
+	t.Run("reports services missing from the catalog", func(t *testing.T) {
+		store := &StubServiceStore{map[string]int{}, nil, []WMService{{Name: "admin"}, {Name: "component:default/admin-web"}, {Name: "retired"}}}
+		_, report := sweep(t, store, http.MethodPost, "")
+
+		if !reflect.DeepEqual(report.Missing, []string{"retired"}) {
+			t.Errorf("got missing %v, want [retired]", report.Missing)
 		}
+	})
+
+	t.Run("dry run is not recorded", func(t *testing.T) {
+		store := &StubServiceStore{map[string]int{}, nil, nil}
+		response, report := sweep(t, store, http.MethodPost, "?dryrun=true")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertBool(t, report.DryRun, true)
+		assertIDEquals(t, len(report.Verified), 4)
+		assertIDEquals(t, len(store.verifyCalls), 0)
+	})
 
-		if store.verifyCalls[0] != service {
-			t.Errorf("did not store correct service got %q want %q", store.verifyCalls[0], service)
+	t.Run("sweeps an inventory file", func(t *testing.T) {
+		cl := mockUpstreamsWith(t, `{type: file, args: {path: "`+writeInventory(t, mockInventory)+`"}}`)
+		store := &StubServiceStore{map[string]int{}, nil, nil}
+		server := NewVerificationServ(store, cl)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v1/sweep?components=true", nil)
+		server.ServeHTTP(response, req)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if !reflect.DeepEqual(store.verifyCalls, []string{"admin-web", "admin", "core"}) {
+			t.Errorf("got verified %v", store.verifyCalls)
 		}
 	})
+
+	t.Run("a component has the tier and skips listed under its name", func(t *testing.T) {
+		cl, reads := mockUpstreamsChecking(t, `
+checks: [{id: owner, type: owner, skip: [admin-web], args: {api: "GITHUB"}}]
+objectives:
+  tiers:
+    tier-2: {minScore: 95}
+services:
+  admin-web: {tier: tier-2}
+`)
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+		store, err := NewFSStore(database)
+		assertNoError(t, err)
+		server := NewVerificationServ(store, cl)
+
+		req, _ := http.NewRequest(http.MethodPost, "/v1/sweep?kind=component", nil)
+		server.ServeHTTP(httptest.NewRecorder(), req)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAlmanacRequest())
+
+		got := getAlmanacFromResponse(t, response.Body)
+		if len(got) != 1 || got[0].SRO == nil {
+			t.Fatalf("got almanac %+v, want admin-web with an SRO", got)
+		}
+		assertString(t, got[0].Name, "component:default/admin-web")
+		assertString(t, got[0].SRO.Tier, "tier-2")
+		assertMultiString(t, reads.List(), nil)
+	})
+
+	t.Run("refuses an unsupported kind", func(t *testing.T) {
+		response, _ := sweep(t, &StubServiceStore{}, http.MethodPost, "?kind=group")
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("refuses a malformed label", func(t *testing.T) {
+		response, _ := sweep(t, &StubServiceStore{}, http.MethodPost, "?label=tier")
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("only answers POST", func(t *testing.T) {
+		response, _ := sweep(t, &StubServiceStore{}, http.MethodGet, "")
+		assertStatus(t, response.Code, http.StatusMethodNotAllowed)
+	})
+
+	t.Run("returns 502 when Backstage can't be listed", func(t *testing.T) {
+		t.Setenv("BACKSTAGE", "http://127.0.0.1:1")
+		response, _ := sweep(t, &StubServiceStore{}, http.MethodPost, "")
+		assertStatus(t, response.Code, http.StatusBadGateway)
+	})
 }
-*/
 
-/* DISABLING FOR OTHER TESTS TO BE FINISHED
+// waivers endpoint
+func TestWaiversHandler(t *testing.T) {
+	store := StubServiceStore{nil, nil, []WMService{
+		{Name: "admin", LastID: 3, Score: 98},
+	}}
+	server := NewVerificationServ(&store, DefaultChecklist())
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	t.Run("adds a waiver to a known service", func(t *testing.T) {
+		body := `{"Check":"owner","Reason":"team reorg","Approver":"sre-lead","Expires":"` + expires + `"}`
+		request := newWaiverReq(http.MethodPost, "admin", body)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusCreated)
+		assertIDEquals(t, len(store.GetWaivers("admin")), 1)
+		assertString(t, store.GetWaivers("admin")[0].Approver, "sre-lead")
+	})
+
+	t.Run("lists waivers with whether they are active", func(t *testing.T) {
+		request := newWaiverReq(http.MethodGet, "admin", "")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got []WaiverStatus
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into slice of WaiverStatus, '%v'", err)
+		}
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+		assertIDEquals(t, len(got), 1)
+		assertBool(t, got[0].Active, true)
+	})
+
+	t.Run("refuses a waiver missing its approver", func(t *testing.T) {
+		body := `{"Check":"owner","Reason":"team reorg","Expires":"` + expires + `"}`
+		request := newWaiverReq(http.MethodPost, "admin", body)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertIDEquals(t, len(store.GetWaivers("admin")), 1)
+	})
+
+	t.Run("refuses a waiver for an unknown check", func(t *testing.T) {
+		body := `{"Check":"ownr","Reason":"team reorg","Approver":"sre-lead","Expires":"` + expires + `"}`
+		request := newWaiverReq(http.MethodPost, "admin", body)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertIDEquals(t, len(store.GetWaivers("admin")), 1)
+	})
+
+	t.Run("returns 404 for an unknown service", func(t *testing.T) {
+		body := `{"Check":"owner","Reason":"team reorg","Approver":"sre-lead","Expires":"` + expires + `"}`
+		request := newWaiverReq(http.MethodPost, "Mattic", body)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
 
 // This saves us from not having to test the temporary InMemoryServiceStore
 // and this code integration test can be reused with some other value for /store/
 func TestRecordingIDsAndRetrievingThem(t *testing.T) {
-	log.Printf("%s", "Begin Database Integration Test")
-
 	database, cleanDatabase := createTempFile(t, `[]`)
 	defer cleanDatabase()
 
@@ -195,9 +630,8 @@ func TestRecordingIDsAndRetrievingThem(t *testing.T) {
 		log.Fatalf("Integration test: problem creating file system service store, %v ", err)
 	}
 
-	server := NewVerificationServ(store)
-	// for some reason i can't tell yet, this must be /admin/
-	service := "verificat"
+	server := NewVerificationServ(store, mockUpstreams(t))
+	service := "admin"
 
 	server.ServeHTTP(httptest.NewRecorder(), newPostIDReq(service))
 	server.ServeHTTP(httptest.NewRecorder(), newPostIDReq(service))
@@ -208,9 +642,90 @@ func TestRecordingIDsAndRetrievingThem(t *testing.T) {
 	assertStatus(t, response.Code, http.StatusOK)
 
 	assertResponseBody(t, response.Body.String(), "LastID for "+service+": 3\n")
+	assertIDEquals(t, store.GetScore(service), 100)
+}
+
+// mockUpstreams stands in for Backstage and GitHub for the length of a test,
+// returning a Checklist with the owner check pointed at the mock GitHub.
+// In the mock catalog, admin is owned by code-owners-admin, see mockSystems.
+func mockUpstreams(t testing.TB) *Checklist {
+	t.Helper()
+	return mockUpstreamsWith(t, "{}")
+}
+
+// mockUpstreamsWith is mockUpstreams reading Services from the given catalog section.
+func mockUpstreamsWith(t testing.TB, catalog string) *Checklist {
+	t.Helper()
+	cl, _ := mockUpstreamsReading(t, catalog)
+	return cl
+}
+
+// mockRepos are the repos in the mock GitHub, every one of them in the maroda org.
+// Each is named after a Service in the mock catalog, unless the catalog gives its repo.
+var mockRepos = []string{"admin-api", "admin-web", "core", "ad-server", "ledger", "ledger-api"}
+
+// mockUpstreamsReading is mockUpstreamsWith, also returning every repo GitHub was asked for.
+// Only mockRepos are served, any other repo is not found.
+func mockUpstreamsReading(t testing.TB, catalog string) (*Checklist, *repoReads) {
+	t.Helper()
+	return mockUpstreamsChecking(t, `{catalog: `+catalog+`, checks: [{id: owner, type: owner, args: {api: "GITHUB"}}]}`)
 }
 
-*/
+// mockUpstreamsChecking is mockUpstreamsReading with the whole checklist given,
+// where GITHUB stands in for the URL of the mock GitHub.
+func mockUpstreamsChecking(t testing.TB, checklist string) (*Checklist, *repoReads) {
+	t.Helper()
+
+	bs := makeMockBackstage("")
+	t.Cleanup(bs.Close)
+	reads := new(repoReads)
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /repos/maroda/repo or /repos/maroda/repo/contents/path
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/repos/maroda/"), "/", 2)
+		reads.add(parts[0])
+		switch {
+		case !slices.Contains(mockRepos, parts[0]):
+			w.WriteHeader(http.StatusNotFound)
+		case len(parts) == 1:
+			fmt.Fprintf(w, `{"name":%q,"default_branch":"main"}`, parts[0])
+		case strings.HasPrefix(parts[1], "contents/"):
+			w.Write([]byte("* @maroda/code-owners-admin\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(gh.Close)
+
+	t.Setenv("BACKSTAGE", bs.URL)
+	t.Setenv("GH_TOKEN", "mock-token")
+
+	cl, err := ParseChecklist([]byte(strings.ReplaceAll(checklist, "GITHUB", gh.URL)))
+	if err != nil {
+		t.Fatalf("mock checklist is invalid, %v", err)
+	}
+	return cl, reads
+}
+
+// repoReads are the repos a mock GitHub was asked for, Checks run concurrently.
+type repoReads struct {
+	mu    sync.Mutex
+	repos []string
+}
+
+func (rr *repoReads) add(repo string) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if !slices.Contains(rr.repos, repo) {
+		rr.repos = append(rr.repos, repo)
+	}
+}
+
+// List is every repo that was read, sorted.
+func (rr *repoReads) List() []string {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return slices.Sorted(slices.Values(rr.repos))
+}
 
 func newGetTriggerIDReq(name string) *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v0/%s", name), nil)
@@ -222,6 +737,11 @@ func newPostIDReq(name string) *http.Request {
 	return req
 }
 
+func newWaiverReq(method, name, body string) *http.Request {
+	req, _ := http.NewRequest(method, fmt.Sprintf("/v1/waivers/%s", name), strings.NewReader(body))
+	return req
+}
+
 func newHomeReq() *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/"), nil)
 	return req
@@ -77,5 +77,38 @@ func hashBarSVG(c int, sd *WMService, sc *SVGCfg) string {
 		h, h, sd.Score,
 		y, sd.Score,
 		y, sd.LastID,
-		y, sd.Name)
+		y, sd.Name) + verdictSVG(y, sd.Verdict) + sroSVG(y, sd.SRO)
+}
+
+// sroSVG displays the SRO margin after the verdict, colored by whether it is met.
+// Services without an Objective have nothing drawn.
+func sroSVG(y int, sro *SROStatus) string {
+	if sro == nil {
+		return ""
+	}
+
+	fill := "palegreen"
+	if !sro.Met {
+		fill = "tomato"
+	}
+	return fmt.Sprintf(`
+    <text x="180" y="%d" font-family="Helvetica" font-size="6" fill="%s" fill-opacity="1">SRO %+d</text>`,
+		y, fill, sro.Margin)
+}
+
+// verdictSVG displays the ready or not-ready verdict at the end of the line.
+// Services that have never been verified have no verdict, so nothing is drawn.
+func verdictSVG(y int, verdict string) string {
+	var fill string
+	switch verdict {
+	case VerdictReady:
+		fill = "palegreen"
+	case VerdictNotReady:
+		fill = "tomato"
+	default:
+		return ""
+	}
+	return fmt.Sprintf(`
+    <text x="150" y="%d" font-family="Helvetica" font-size="6" font-weight="bold" fill="%s" fill-opacity="1">%s</text>`,
+		y, fill, verdict)
 }
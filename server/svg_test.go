@@ -8,8 +8,8 @@ import (
 func TestBuildSVG(t *testing.T) {
 	// Create a test database for drawing SVGs
 	database, cleanDatabase := createTempFile(t, `[
-			{"Name": "Mattic", "LastID": 10, "Score": 99},
-			{"Name": "Craque", "LastID": 4, "Score": 98}]`)
+			{"Name": "Mattic", "LastID": 10, "Score": 99, "Verdict": "ready"},
+			{"Name": "Craque", "LastID": 4, "Score": 98, "Verdict": "not-ready"}]`)
 	defer cleanDatabase()
 	store, err := NewFSStore(database)
 	assertNoError(t, err)
@@ -31,6 +31,8 @@ func TestBuildSVG(t *testing.T) {
 		"M5 31h98v10H5z",
 		"y=\"25\"",
 		"y=\"39\"",
+		`fill="palegreen" fill-opacity="1">ready</text>`,
+		`fill="tomato" fill-opacity="1">not-ready</text>`,
 	}
 
 	for _, want := range wants {
@@ -38,6 +40,27 @@ func TestBuildSVG(t *testing.T) {
 	}
 }
 
+func TestVerdictSVG(t *testing.T) {
+	t.Run("no verdict draws nothing", func(t *testing.T) {
+		assertString(t, verdictSVG(25, ""), "")
+	})
+
+	t.Run("verdict is drawn on the text line", func(t *testing.T) {
+		assertXML(t, verdictSVG(25, VerdictReady), `x="150" y="25"`)
+	})
+}
+
+func TestSroSVG(t *testing.T) {
+	t.Run("no objective draws nothing", func(t *testing.T) {
+		assertString(t, sroSVG(25, nil), "")
+	})
+
+	t.Run("margin is signed and colored", func(t *testing.T) {
+		assertXML(t, sroSVG(25, &SROStatus{Met: true, Margin: 3}), `fill="palegreen" fill-opacity="1">SRO +3</text>`)
+		assertXML(t, sroSVG(25, &SROStatus{Met: false, Margin: -2}), `fill="tomato" fill-opacity="1">SRO -2</text>`)
+	})
+}
+
 func assertXML(t *testing.T, xml, want string) {
 	t.Helper()
 	if !strings.Contains(xml, want) {
@@ -0,0 +1,115 @@
+package verificat
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// SweepReport is what happened to every Service in the catalog on one sweep.
+type SweepReport struct {
+	Catalog  int           // How many Services the catalog listed
+	Selected int           // How many of those the Selector matched
+	Verified []SweepResult // Every Service verified, in order, including the Components of each System
+	Missing  []string      // In the almanac, but no longer in the catalog
+	DryRun   bool          `json:",omitempty"`
+}
+
+// SweepResult is the outcome of verifying one Service on a sweep.
+type SweepResult struct {
+	Service string
+	Kind    string
+	Score   int
+	Verdict string
+}
+
+// sweep lists every Service in the catalog and verifies each one of the given kinds the Selector matches.
+// Every kind is listed, so a Service is only missing when it's gone from the catalog, not when its kind wasn't selected.
+// Systems are verified first, along with their Components, which aren't verified again.
+// Services are verified one at a time, so a large catalog doesn't flood GitHub,
+// and each is added to the almanac by its first recorded run.
+func (v *VerificationServ) sweep(ctx context.Context, sl SvcLister, sel Selector, kinds []string, dryRun bool) (*SweepReport, error) {
+	entries, err := sl.ListSvc(ctx, entityKinds...)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []CatalogEntry
+	for _, entry := range entries {
+		if slices.Contains(kinds, entry.Kind) && sel.Matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b CatalogEntry) int {
+		return cmp.Compare(kindOrder(a.Kind), kindOrder(b.Kind))
+	})
+
+	report := &SweepReport{Catalog: len(entries), Selected: len(selected), DryRun: dryRun}
+
+	listed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		listed[entry.Service] = true
+	}
+	for _, service := range v.store.GetAlmanac() {
+		if !listed[service.Name] {
+			report.Missing = append(report.Missing, service.Name)
+		}
+	}
+	if len(report.Missing) > 0 {
+		slog.Warn("Services Missing From Catalog", slog.Any("Services", report.Missing))
+	}
+
+	done := make(map[string]bool)
+	for _, entry := range selected {
+		if done[entry.Service] {
+			continue
+		}
+
+		// A cancelled sweep stops here, what was verified so far is reported
+		if ctx.Err() != nil {
+			slog.Warn("Sweep Cancelled", slog.Int("Verified", len(report.Verified)), slog.Any("Error", ctx.Err()))
+			break
+		}
+
+		// The list already has what ReadSvc would read, so the catalog isn't asked again
+		svcconf := &SvcConfig{
+			Service:  entry.Service,
+			Name:     entry.Name,
+			Datetime: time.Now().Unix(),
+			Owner:    entry.Owner,
+			Repos:    slices.Clone(entry.Repos),
+		}
+		if entry.Kind == "system" {
+			svcconf.Members = systemMembers(entry.Name, slices.Values(entries))
+		}
+
+		run := v.verify(ctx, svcconf, dryRun, io.Discard)
+		for _, m := range run.Members {
+			report.Verified = append(report.Verified, SweepResult{Service: m.Service, Kind: "component", Score: m.Score, Verdict: m.Verdict})
+			done[m.Service] = true
+		}
+		report.Verified = append(report.Verified, SweepResult{
+			Service: entry.Service,
+			Kind:    entry.Kind,
+			Score:   run.Score,
+			Verdict: run.Verdict,
+		})
+		done[entry.Service] = true
+	}
+
+	slog.Info("Sweep Complete",
+		slog.Int("Catalog", report.Catalog),
+		slog.Int("Selected", report.Selected),
+		slog.Int("Verified", len(report.Verified)),
+		slog.Int("Missing", len(report.Missing)),
+	)
+	return report, nil
+}
+
+// kindOrder puts Systems first, then every other kind in the order of entityKinds.
+func kindOrder(kind string) int {
+	return slices.Index(entityKinds, kind)
+}
@@ -0,0 +1,64 @@
+package verificat
+
+import (
+	"errors"
+	"time"
+)
+
+var ServiceNotFound = errors.New("service not found")
+
+// Waiver accepts a known failure of a Check for a Service until it Expires.
+// While active, the failed Check shows as waived and costs nothing.
+type Waiver struct {
+	Check    string    // The ID of the Check being waived
+	Reason   string    // Why the failure is accepted
+	Approver string    // Who accepted it
+	Expires  time.Time // When the Waiver stops applying, RFC 3339
+	Created  time.Time // Set when the Waiver is stored
+}
+
+// Active is true until the Waiver Expires.
+func (w *Waiver) Active(now time.Time) bool {
+	return now.Before(w.Expires)
+}
+
+// Validate makes sure a new Waiver has everything it needs.
+func (w *Waiver) Validate(now time.Time) error {
+	var errs []error
+	if w.Check == "" {
+		errs = append(errs, errors.New("waiver needs a Check"))
+	}
+	if w.Reason == "" {
+		errs = append(errs, errors.New("waiver needs a Reason"))
+	}
+	if w.Approver == "" {
+		errs = append(errs, errors.New("waiver needs an Approver"))
+	}
+	if !w.Active(now) {
+		errs = append(errs, errors.New("waiver Expires must be in the future"))
+	}
+	return errors.Join(errs...)
+}
+
+// WaiverStatus is a Waiver as listed by the API, showing whether it still applies.
+type WaiverStatus struct {
+	Waiver
+	Active bool
+}
+
+// ApplyWaivers marks each failed result with an active Waiver as waived.
+// Expired Waivers are ignored, so the failure counts again.
+func ApplyWaivers(results []*CheckResult, waivers []Waiver, now time.Time) {
+	for _, result := range results {
+		if result.Status != StatusFail {
+			continue
+		}
+		for i := range waivers {
+			if waivers[i].Check == result.ID && waivers[i].Active(now) {
+				result.Status = StatusWaived
+				result.Waiver = &waivers[i]
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package verificat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaiver_Validate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("a complete waiver is valid", func(t *testing.T) {
+		w := Waiver{Check: "owner", Reason: "reorg", Approver: "sre", Expires: now.Add(time.Hour)}
+		assertNoError(t, w.Validate(now))
+	})
+
+	t.Run("every field is required", func(t *testing.T) {
+		w := Waiver{Expires: now.Add(time.Hour)}
+		assertHasError(t, w.Validate(now))
+	})
+
+	t.Run("a waiver that has already expired is refused", func(t *testing.T) {
+		w := Waiver{Check: "owner", Reason: "reorg", Approver: "sre", Expires: now.Add(-time.Hour)}
+		assertHasError(t, w.Validate(now))
+	})
+}
+
+func TestApplyWaivers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	waivers := []Waiver{
+		{Check: "owner", Expires: now.Add(time.Hour)},
+		{Check: "runbook", Expires: now.Add(-time.Hour)},
+	}
+
+	results := []*CheckResult{
+		{ID: "owner", Status: StatusFail},
+		{ID: "runbook", Status: StatusFail},
+		{ID: "alerts", Status: StatusFail},
+		{ID: "owner", Status: StatusPass},
+	}
+
+	ApplyWaivers(results, waivers, now)
+
+	want := []Status{StatusWaived, StatusFail, StatusFail, StatusPass}
+	for i, result := range results {
+		assertString(t, string(result.Status), string(want[i]))
+	}
+	if results[1].Waiver != nil || results[3].Waiver != nil {
+		t.Errorf("Only waived results should point at their Waiver")
+	}
+	assertIDEquals(t, len(counted(results)), 3)
+}